@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/connector"
+	"github.com/storj-thirdparty/connector-framework/pkg/source/local"
+)
+
+// configCmd groups the commands managing configuration files.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Commands to manage configuration files.",
+}
+
+// configValidateCmd represents the config validate command.
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Command to check configuration files without connecting to storjV3 network.",
+	Long: `Command to load and validate the Storj configuration, and the source configuration if given, without connecting to the Storj network.
+Every invalid field is reported with its JSON path.`,
+	Args: cobra.NoArgs,
+	RunE: configValidate,
+}
+
+// configInitCmd represents the config init command.
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Command to create a Storj configuration file interactively.",
+	Long: `Command to prompt for the satellite, API key or access grant, bucket, encryption passphrase and share permissions,
+check them by connecting to the Storj network and write them to a Storj configuration file only readable by its owner.
+The bucket is created if it does not exist.`,
+	Args: cobra.NoArgs,
+	RunE: configInit,
+}
+
+func init() {
+
+	// Setup the config commands with their flags.
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configInitCmd)
+	configInitCmd.Flags().StringP("storj", "u", "././config/storj_config.json", "full filepath of the Storj V3 configuration to write; .yaml, .yml and .toml files are written in that format.")
+	configInitCmd.Flags().BoolP("force", "f", false, "overwrite an existing configuration file.")
+	configCmd.AddCommand(configValidateCmd)
+	configValidateCmd.Flags().StringP("storj", "u", "././config/storj_config.json", "full filepath contaning Storj V3 configuration.")
+	configValidateCmd.Flags().String("profile-name", "", "name of the profile of the Storj configuration to use (default the defaultProfile of the configuration).")
+	configValidateCmd.Flags().StringP("local", "l", "", "full filepath contaning source configuration (default does not check the source configuration).")
+	configValidateCmd.Flags().String("source", local.Name, "name of the registered source the source configuration belongs to.")
+}
+
+func configValidate(cmd *cobra.Command, args []string) error {
+
+	// Process arguments from the CLI.
+	fullFileNameStorj := configFile(cmd, "storj")
+	profileName, _ := cmd.Flags().GetString("profile-name")
+	sourceConfigFilePath := configFile(cmd, "local")
+	sourceName, _ := cmd.Flags().GetString("source")
+	cmd.SilenceUsage = true
+	ctx := cmd.Context()
+
+	storjConfig, err := connector.LoadStorjProfile(ctx, fullFileNameStorj, profileName)
+	if err != nil {
+		return err
+	}
+	printStorjConfig(cmd, cmd.OutOrStdout(), fullFileNameStorj, storjConfig)
+
+	if sourceConfigFilePath != "" {
+		src, err := connector.LoadSource(ctx, sourceName, sourceConfigFilePath)
+		if err != nil {
+			return err
+		}
+		printSourceConfig(cmd, sourceName, sourceConfigFilePath, src)
+		if err := src.Close(); err != nil {
+			return &connector.SourceError{Source: sourceName, Err: err}
+		}
+	}
+
+	fmt.Println("Configuration is valid.")
+	return nil
+}
+
+func configInit(cmd *cobra.Command, args []string) error {
+
+	// Process arguments from the CLI.
+	fullFileNameStorj, _ := cmd.Flags().GetString("storj")
+	force, _ := cmd.Flags().GetBool("force")
+	cmd.SilenceUsage = true
+	ctx := cmd.Context()
+
+	if _, err := os.Stat(fullFileNameStorj); err == nil && !force {
+		return &connector.ConfigError{Path: fullFileNameStorj, Err: errors.New("file exists, use --force to overwrite it")}
+	}
+
+	storjConfig, useAccessKey, err := promptStorjConfig(newPrompter(cmd.InOrStdin(), cmd.OutOrStdout()))
+	if err != nil {
+		return err
+	}
+	if err = storjConfig.Validate(); err != nil {
+		return &connector.ConfigError{Err: err}
+	}
+
+	// Check the access before writing it.
+	session, err := connector.OpenSession(ctx, storjConfig, useAccessKey)
+	if err != nil {
+		return err
+	}
+	if err = session.Close(); err != nil {
+		return err
+	}
+
+	if err = connector.WriteConfigFile(fullFileNameStorj, storjConfig); err != nil {
+		return err
+	}
+	fmt.Println("Wrote Storj configuration to the", fullFileNameStorj, "file.")
+	return nil
+}
+
+// promptStorjConfig asks for the values of a new Storj configuration.
+// It reports whether the configuration holds an access grant to connect with.
+func promptStorjConfig(p *prompter) (storjConfig connector.ConfigStorj, useAccessKey bool, err error) {
+	storjConfig.KeyTemplate = connector.DefaultKeyTemplate
+	storjConfig.Workers = connector.DefaultWorkers
+
+	if useAccessKey, err = p.Bool("Connect with an access grant instead of an API key?", false); err != nil {
+		return storjConfig, false, err
+	}
+	if useAccessKey {
+		if storjConfig.SerializedAccess, err = p.Secret("Access grant"); err != nil {
+			return storjConfig, false, err
+		}
+	} else {
+		if storjConfig.Satellite, err = p.String("Satellite address", "us1.storj.io:7777"); err != nil {
+			return storjConfig, false, err
+		}
+		if storjConfig.APIKey, err = p.Secret("API key"); err != nil {
+			return storjConfig, false, err
+		}
+		if storjConfig.EncryptionPassphrase, err = p.Secret("Encryption passphrase"); err != nil {
+			return storjConfig, false, err
+		}
+	}
+
+	if storjConfig.Bucket, err = p.Required("Bucket name"); err != nil {
+		return storjConfig, false, err
+	}
+	if storjConfig.UploadPath, err = p.String("Upload path within the bucket", ""); err != nil {
+		return storjConfig, false, err
+	}
+
+	// Permissions of the access shared with store --share.
+	permissions := []struct {
+		question string
+		def      bool
+		value    *connector.Bool
+	}{
+		{"Allow downloads with shared access?", true, &storjConfig.AllowDownload},
+		{"Allow uploads with shared access?", false, &storjConfig.AllowUpload},
+		{"Allow listing with shared access?", true, &storjConfig.AllowList},
+		{"Allow deletes with shared access?", false, &storjConfig.AllowDelete},
+	}
+	for _, permission := range permissions {
+		allow, err := p.Bool(permission.question, permission.def)
+		if err != nil {
+			return storjConfig, false, err
+		}
+		*permission.value = connector.Bool(allow)
+	}
+	for {
+		notAfter, err := p.String("Shared access expiry, e.g. +720h or 2021-03-31T15:04:05Z (empty never expires)", "")
+		if err != nil {
+			return storjConfig, false, err
+		}
+		if err = storjConfig.NotAfter.UnmarshalText([]byte(notAfter)); err == nil {
+			break
+		}
+		fmt.Fprintln(p.out, err)
+	}
+
+	return storjConfig, useAccessKey, nil
+}
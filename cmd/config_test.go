@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/connector"
+)
+
+func TestPromptStorjConfig(t *testing.T) {
+	answers := strings.Join([]string{
+		"",           // API key instead of an access grant
+		"",           // default satellite
+		"key",        // API key
+		"passphrase", // encryption passphrase
+		"",           // bucket is required
+		"backups",    // bucket
+		"db01/",      // upload path
+		"",           // allow downloads
+		"maybe",      // not a yes or no answer
+		"yes",        // allow uploads
+		"n",          // allow listing
+		"",           // allow deletes
+		"in a month", // invalid expiry
+		"+720h",      // expiry
+	}, "\n")
+	var out bytes.Buffer
+	config, useAccessKey, err := promptStorjConfig(newPrompter(strings.NewReader(answers), &out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if useAccessKey {
+		t.Error("access grant selected")
+	}
+
+	want := connector.ConfigStorj{
+		APIKey:               "key",
+		Satellite:            "us1.storj.io:7777",
+		Bucket:               "backups",
+		UploadPath:           "db01/",
+		EncryptionPassphrase: "passphrase",
+		AllowDownload:        true,
+		AllowUpload:          true,
+		KeyTemplate:          connector.DefaultKeyTemplate,
+		Workers:              connector.DefaultWorkers,
+	}
+	got := config
+	got.NotAfter = connector.Time{}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	for _, message := range []string{"A value is required.", "Please answer yes or no.", `invalid time "in a month"`} {
+		if !strings.Contains(out.String(), message) {
+			t.Errorf("output does not contain %q:\n%s", message, out.String())
+		}
+	}
+
+	// The expiry is resolved now, but written as given.
+	if until := time.Until(config.NotAfter.Time); until < 719*time.Hour || until > 720*time.Hour {
+		t.Errorf("expiry is %v from now", until)
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte(`"notAfter":"+720h"`)) {
+		t.Errorf("expiry not written as given: %s", data)
+	}
+}
+
+func TestPromptStorjConfigAccessGrant(t *testing.T) {
+	answers := "y\ngrant\nbackups\n\n\n\n\n\n\n"
+	config, useAccessKey, err := promptStorjConfig(newPrompter(strings.NewReader(answers), &bytes.Buffer{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !useAccessKey || config.SerializedAccess != "grant" || config.APIKey != "" || config.Bucket != "backups" || !config.NotAfter.IsZero() {
+		t.Errorf("got %+v, access grant %v", config, useAccessKey)
+	}
+
+	// Running out of answers is an error rather than an endless loop.
+	if _, _, err := promptStorjConfig(newPrompter(strings.NewReader("y\n"), &bytes.Buffer{})); err == nil {
+		t.Error("no error at the end of the input")
+	}
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/connector"
+)
+
+// Exit codes returned by the CLI for the different kinds of failures.
+const (
+	exitFailure       = 1
+	exitConfigError   = 2
+	exitConnectError  = 3
+	exitSourceError   = 4
+	exitTransferError = 5
+)
+
+// exitCode maps an error returned by a command to the process exit code.
+func exitCode(err error) int {
+	var (
+		configErr   *connector.ConfigError
+		connectErr  *connector.ConnectError
+		sourceErr   *connector.SourceError
+		transferErr *connector.TransferError
+	)
+	switch {
+	case errors.As(err, &configErr):
+		return exitConfigError
+	case errors.As(err, &connectErr):
+		return exitConnectError
+	case errors.As(err, &sourceErr):
+		return exitSourceError
+	case errors.As(err, &transferErr):
+		return exitTransferError
+	default:
+		return exitFailure
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"storj.io/uplink"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/connector"
+)
+
+// listCmd represents the list command.
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Command to list backups stored on storjV3 network.",
+	Long: `Command to list the backups stored under the upload path of given Storj Bucket with their size, creation time and custom metadata.
+With --runs the runs of the store command recorded in manifests are listed instead, and --run lists the backups of a single run.`,
+	RunE: storjList,
+}
+
+func init() {
+
+	// Setup the list command with its flags.
+	rootCmd.AddCommand(listCmd)
+	var defaultStorjFile string
+	listCmd.Flags().BoolP("accesskey", "a", false, "Connect to storj using access key(default connection method is by using API Key).")
+	listCmd.Flags().BoolP("debug", "d", false, "Collect simple code stat: time & memory alloc & stack")
+	listCmd.Flags().StringVarP(&defaultStorjFile, "storj", "u", "././config/storj_config.json", "full filepath contaning Storj V3 configuration.")
+	listCmd.Flags().String("profile-name", "", "name of the profile of the Storj configuration to use (default the defaultProfile of the configuration).")
+	listCmd.Flags().StringP("prefix", "k", "", "only list backups whose key, relative to the upload path, starts with the prefix.")
+	listCmd.Flags().BoolP("recursive", "r", false, "list all backups below the prefix instead of collapsing them into directories.")
+	listCmd.Flags().String("run", "", "only list the backups uploaded by the run with the given ID.")
+	listCmd.Flags().Bool("runs", false, "list the runs recorded in manifests instead of the backups.")
+	listCmd.Flags().Bool("json", false, "print the backups as JSON.")
+}
+
+// backupInfo is the printable description of a listed backup.
+type backupInfo struct {
+	Key      string            `json:"key"`
+	IsPrefix bool              `json:"isPrefix"`
+	Size     int64             `json:"size"`
+	Created  time.Time         `json:"created"`
+	Expires  time.Time         `json:"expires"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func storjList(cmd *cobra.Command, args []string) error {
+
+	// Process arguments from the CLI.
+	fullFileNameStorj := configFile(cmd, "storj")
+	profileName, _ := cmd.Flags().GetString("profile-name")
+	useAccessKey, _ := cmd.Flags().GetBool("accesskey")
+	prefix, _ := cmd.Flags().GetString("prefix")
+	recursive, _ := cmd.Flags().GetBool("recursive")
+	runID, _ := cmd.Flags().GetString("run")
+	listRuns, _ := cmd.Flags().GetBool("runs")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	useDebug, _ = cmd.Flags().GetBool("debug")
+	cmd.SilenceUsage = true
+	ctx := connector.WithTracer(cmd.Context(), traceMetric)
+
+	// Progress output of the configuration and connection steps
+	// would corrupt the JSON document, so send it to stderr instead.
+	out, progress := cmd.OutOrStdout(), cmd.OutOrStdout()
+	if asJSON {
+		progress = cmd.ErrOrStderr()
+	}
+	ctx = connector.WithProgress(ctx, progress)
+
+	defer func() {
+		if useDebug {
+			err := saveCollectedMetrics(progress, collectedMetrics)
+			if err != nil {
+				fmt.Fprintf(progress, "failed to save metrics %s\n", err)
+			}
+		}
+	}()
+
+	// Read storj network configurations from and external file and create a storj configuration object.
+	storjConfig, err := connector.LoadStorjProfile(ctx, fullFileNameStorj, profileName)
+	if err != nil {
+		return err
+	}
+	printStorjConfig(cmd, progress, fullFileNameStorj, storjConfig)
+
+	// Connect to storj network using the specified credentials.
+	session, err := connector.OpenSession(ctx, storjConfig, useAccessKey)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := session.Close(); err != nil {
+			fmt.Fprintf(progress, "failed to close session %s", err)
+		}
+	}()
+
+	if listRuns {
+		manifests, err := connector.ListManifests(ctx, session.Project, storjConfig)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(manifests)
+		}
+		return printRuns(out, manifests)
+	}
+
+	var objects []*uplink.Object
+	if runID != "" {
+		var manifest *connector.Manifest
+		if manifest, err = connector.LoadManifest(ctx, session.Project, storjConfig, runID); err == nil {
+			objects, err = connector.ManifestBackups(ctx, session.Project, storjConfig, manifest)
+		}
+	} else {
+		objects, err = connector.ListBackups(ctx, session.Project, storjConfig, prefix, recursive)
+	}
+	if err != nil {
+		return err
+	}
+
+	backups := make([]backupInfo, 0, len(objects))
+	for _, object := range objects {
+		backups = append(backups, backupInfo{
+			Key:      object.Key,
+			IsPrefix: object.IsPrefix,
+			Size:     object.System.ContentLength,
+			Created:  object.System.Created,
+			Expires:  object.System.Expires,
+			Metadata: object.Custom,
+		})
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(backups)
+	}
+
+	return printBackups(out, backups)
+}
+
+// printBackups prints the backups as a table.
+func printBackups(out io.Writer, backups []backupInfo) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tSIZE\tCREATED\tMETADATA")
+	for _, backup := range backups {
+		if backup.IsPrefix {
+			fmt.Fprintf(writer, "%s\tPRE\t\t\n", backup.Key)
+			continue
+		}
+		fmt.Fprintf(writer, "%s\t%d\t%s\t%s\n", backup.Key, backup.Size, backup.Created.Format(time.RFC3339), formatMetadata(backup.Metadata))
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "%d backup(s) listed.\n", len(backups))
+	return err
+}
+
+// printRuns prints the runs as a table.
+func printRuns(out io.Writer, manifests []*connector.Manifest) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "RUN\tSTARTED\tFINISHED\tSOURCE\tOBJECTS\tFAILED")
+	for _, manifest := range manifests {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\t%d\n", manifest.RunID, manifest.Started.Format(time.RFC3339),
+			manifest.Finished.Format(time.RFC3339), manifest.Source, len(manifest.Objects), manifest.Failed)
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "%d run(s) listed.\n", len(manifests))
+	return err
+}
+
+// formatMetadata formats custom metadata as sorted key=value pairs.
+func formatMetadata(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for key, value := range metadata {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/google/uuid"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
 	"runtime"
+	"sync"
 	"time"
 )
 
@@ -40,8 +42,26 @@ func (m *Metric) end() {
 	m.EndStack = bToMb(ms.StackInuse)
 }
 
-//saveCollectedMetrics dumps data to local json file
-func saveCollectedMetrics(metrics []*Metric) error {
+//collectedMetricsMu guards collectedMetrics, which parallel uploads append to
+var collectedMetricsMu sync.Mutex
+
+//traceMetric records a metric for the traced function when debug mode is enabled
+func traceMetric(function string) func() {
+	if !useDebug {
+		return func() {}
+	}
+	metric := &Metric{Function: function}
+	metric.start()
+	return func() {
+		metric.end()
+		collectedMetricsMu.Lock()
+		collectedMetrics = append(collectedMetrics, metric)
+		collectedMetricsMu.Unlock()
+	}
+}
+
+//saveCollectedMetrics dumps data to local json file and reports its path on out
+func saveCollectedMetrics(out io.Writer, metrics []*Metric) error {
 	if len(metrics) == 0 {
 		return nil
 	}
@@ -60,7 +80,9 @@ func saveCollectedMetrics(metrics []*Metric) error {
 	metricsPath := path.Join(p, fmt.Sprintf("%s.json", uuid.New().String()))
 	err = ioutil.WriteFile(metricsPath, byteArr, 0644)
 
-	fmt.Printf("metrcis saved to %s", metricsPath)
+	if err == nil {
+		fmt.Fprintf(out, "metrics saved to %s\n", metricsPath)
+	}
 	return err
 
 }
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// prompter asks questions on out and reads the answers from in.
+// Secrets are read without echo when in is the terminal.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	// terminal is the file descriptor of in if it is a terminal, or -1.
+	terminal int
+}
+
+// newPrompter returns a prompter reading from in and writing to out.
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	p := &prompter{in: bufio.NewReader(in), out: out, terminal: -1}
+	if file, ok := in.(*os.File); ok && terminal.IsTerminal(int(file.Fd())) {
+		p.terminal = int(file.Fd())
+	}
+	return p
+}
+
+// readLine reads an answer without its line ending.
+// The last answer may end at the end of the input.
+func (p *prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// String asks for a value, returning def if the answer is empty.
+func (p *prompter) String(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	answer, err := p.readLine()
+	if err != nil {
+		return "", err
+	}
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// Required asks for a value until a non-empty answer is given.
+func (p *prompter) Required(question string) (string, error) {
+	for {
+		answer, err := p.String(question, "")
+		if err != nil || answer != "" {
+			return answer, err
+		}
+		fmt.Fprintln(p.out, "A value is required.")
+	}
+}
+
+// Secret asks for a non-empty value without echoing it on a terminal.
+func (p *prompter) Secret(question string) (string, error) {
+	if p.terminal < 0 {
+		return p.Required(question)
+	}
+	for {
+		fmt.Fprintf(p.out, "%s: ", question)
+		answer, err := terminal.ReadPassword(p.terminal)
+		fmt.Fprintln(p.out)
+		if err != nil {
+			return "", err
+		}
+		if len(answer) > 0 {
+			return string(answer), nil
+		}
+		fmt.Fprintln(p.out, "A value is required.")
+	}
+}
+
+// Bool asks a yes or no question, returning def if the answer is empty.
+func (p *prompter) Bool(question string, def bool) (bool, error) {
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+	for {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, choices)
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		if value, err := strconv.ParseBool(answer); err == nil {
+			return value, nil
+		}
+		fmt.Fprintln(p.out, "Please answer yes or no.")
+	}
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/connector"
+)
+
+// pruneCmd represents the prune command.
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Command to delete old backups from storjV3 network.",
+	Long: `Command to delete the backups stored under the upload path of given Storj Bucket that are not kept by the retention policy.
+Every run recorded in a manifest is one backup, holding the objects it uploaded that no later run overwrote,
+and its manifest is deleted together with its objects. Objects not recorded in any manifest are only pruned if
+the keyTemplate starts with a directory named after the run, such as {{.Timestamp}}/, in which case every entry
+directly below the upload path is one backup; otherwise they are kept. The policy is read from the "retention"
+section of the Storj configuration and can be overridden with flags.`,
+	RunE: storjPrune,
+}
+
+func init() {
+
+	// Setup the prune command with its flags.
+	rootCmd.AddCommand(pruneCmd)
+	var defaultStorjFile string
+	pruneCmd.Flags().BoolP("accesskey", "a", false, "Connect to storj using access key(default connection method is by using API Key).")
+	pruneCmd.Flags().BoolP("debug", "d", false, "Collect simple code stat: time & memory alloc & stack")
+	pruneCmd.Flags().StringVarP(&defaultStorjFile, "storj", "u", "././config/storj_config.json", "full filepath contaning Storj V3 configuration.")
+	pruneCmd.Flags().String("profile-name", "", "name of the profile of the Storj configuration to use (default the defaultProfile of the configuration).")
+	pruneCmd.Flags().Bool("dry-run", false, "only print the backups that would be deleted.")
+	pruneCmd.Flags().Int("keep-last", 0, "keep the last n backups.")
+	pruneCmd.Flags().Duration("keep-within", 0, "keep the backups created within the duration, e.g. `720h`.")
+	pruneCmd.Flags().Int("keep-daily", 0, "keep the newest backup of each of the last n days.")
+	pruneCmd.Flags().Int("keep-weekly", 0, "keep the newest backup of each of the last n weeks.")
+	pruneCmd.Flags().Int("keep-monthly", 0, "keep the newest backup of each of the last n months.")
+}
+
+func storjPrune(cmd *cobra.Command, args []string) error {
+
+	// Process arguments from the CLI.
+	fullFileNameStorj := configFile(cmd, "storj")
+	profileName, _ := cmd.Flags().GetString("profile-name")
+	useAccessKey, _ := cmd.Flags().GetBool("accesskey")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	useDebug, _ = cmd.Flags().GetBool("debug")
+	cmd.SilenceUsage = true
+	ctx := connector.WithTracer(cmd.Context(), traceMetric)
+
+	defer func() {
+		if useDebug {
+			err := saveCollectedMetrics(cmd.OutOrStdout(), collectedMetrics)
+			if err != nil {
+				fmt.Printf("failed to save metrics %s\n", err)
+			}
+		}
+	}()
+
+	// Read storj network configurations from and external file and create a storj configuration object.
+	storjConfig, err := connector.LoadStorjProfile(ctx, fullFileNameStorj, profileName)
+	if err != nil {
+		return err
+	}
+	printStorjConfig(cmd, cmd.OutOrStdout(), fullFileNameStorj, storjConfig)
+
+	// Retention flags given on the command line replace the configured policy.
+	policy := storjConfig.Retention
+	if cmd.Flags().Changed("keep-last") || cmd.Flags().Changed("keep-within") || cmd.Flags().Changed("keep-daily") ||
+		cmd.Flags().Changed("keep-weekly") || cmd.Flags().Changed("keep-monthly") {
+		policy = connector.RetentionPolicy{}
+		policy.KeepLast, _ = cmd.Flags().GetInt("keep-last")
+		keepWithin, _ := cmd.Flags().GetDuration("keep-within")
+		policy.KeepWithin = connector.Duration(keepWithin)
+		policy.KeepDaily, _ = cmd.Flags().GetInt("keep-daily")
+		policy.KeepWeekly, _ = cmd.Flags().GetInt("keep-weekly")
+		policy.KeepMonthly, _ = cmd.Flags().GetInt("keep-monthly")
+	}
+
+	// Connect to storj network using the specified credentials.
+	session, err := connector.OpenSession(ctx, storjConfig, useAccessKey)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := session.Close(); err != nil {
+			fmt.Printf("failed to close session %s", err)
+		}
+	}()
+
+	return connector.PruneBackups(ctx, session.Project, storjConfig, policy, dryRun)
+}
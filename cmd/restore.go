@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/connector"
+)
+
+// restoreCmd represents the restore command.
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Command to download backups from storjV3 network.",
+	Long: `Command to download backups stored under the upload path of given Storj Bucket to a local destination.
+Objects are written below the destination keeping their path relative to the upload path.`,
+	RunE: storjRestore,
+}
+
+func init() {
+
+	// Setup the restore command with its flags.
+	rootCmd.AddCommand(restoreCmd)
+	var defaultStorjFile string
+	restoreCmd.Flags().BoolP("accesskey", "a", false, "Connect to storj using access key(default connection method is by using API Key).")
+	restoreCmd.Flags().BoolP("debug", "d", false, "Collect simple code stat: time & memory alloc & stack")
+	restoreCmd.Flags().StringVarP(&defaultStorjFile, "storj", "u", "././config/storj_config.json", "full filepath contaning Storj V3 configuration.")
+	restoreCmd.Flags().String("profile-name", "", "name of the profile of the Storj configuration to use (default the defaultProfile of the configuration).")
+	restoreCmd.Flags().StringP("key", "k", "", "object key or prefix, relative to the upload path, to restore (default restores everything).")
+	restoreCmd.Flags().StringP("destination", "o", ".", "local directory to restore the backups into.")
+	restoreCmd.Flags().Bool("latest", false, "restore only the objects of the most recent backup matching the key.")
+	restoreCmd.Flags().String("at", "", "restore only the objects of the latest backup created at or before the given RFC3339 timestamp.")
+	restoreCmd.Flags().String("run", "", "restore only the backups uploaded by the run with the given ID, see list --runs.")
+}
+
+func storjRestore(cmd *cobra.Command, args []string) error {
+
+	// Process arguments from the CLI.
+	fullFileNameStorj := configFile(cmd, "storj")
+	profileName, _ := cmd.Flags().GetString("profile-name")
+	useAccessKey, _ := cmd.Flags().GetBool("accesskey")
+	key, _ := cmd.Flags().GetString("key")
+	destination, _ := cmd.Flags().GetString("destination")
+	latest, _ := cmd.Flags().GetBool("latest")
+	at, _ := cmd.Flags().GetString("at")
+	runID, _ := cmd.Flags().GetString("run")
+	if runID != "" && (latest || at != "") {
+		return errors.New("--run cannot be combined with --latest or --at")
+	}
+	useDebug, _ = cmd.Flags().GetBool("debug")
+
+	var atTime time.Time
+	if at != "" {
+		var err error
+		atTime, err = time.Parse(time.RFC3339, at)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp: %w", err)
+		}
+	}
+	cmd.SilenceUsage = true
+	ctx := connector.WithTracer(cmd.Context(), traceMetric)
+
+	defer func() {
+		if useDebug {
+			err := saveCollectedMetrics(cmd.OutOrStdout(), collectedMetrics)
+			if err != nil {
+				fmt.Printf("failed to save metrics %s\n", err)
+			}
+		}
+	}()
+
+	// Read storj network configurations from and external file and create a storj configuration object.
+	storjConfig, err := connector.LoadStorjProfile(ctx, fullFileNameStorj, profileName)
+	if err != nil {
+		return err
+	}
+	printStorjConfig(cmd, cmd.OutOrStdout(), fullFileNameStorj, storjConfig)
+
+	// Connect to storj network using the specified credentials.
+	session, err := connector.OpenSession(ctx, storjConfig, useAccessKey)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := session.Close(); err != nil {
+			fmt.Printf("failed to close session %s", err)
+		}
+	}()
+
+	fmt.Printf("Initiating restore.\n")
+	err = connector.RestoreBackups(ctx, session.Project, storjConfig, connector.RestoreOptions{
+		Key:         key,
+		Destination: destination,
+		Latest:      latest,
+		At:          atTime,
+		RunID:       runID,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restore complete.\n\n")
+	return nil
+}
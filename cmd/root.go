@@ -1,9 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"github.com/spf13/cobra"
+	"io"
 	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/connector"
+	"github.com/storj-thirdparty/connector-framework/pkg/source"
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -11,17 +19,67 @@ var rootCmd = &cobra.Command{
 	Use:   "connector-framework", //****Change connector name here****
 	Short: "Backup data to the decentralized Storj V3 network.",
 	Long:  `connector-framework  - Backup your data to the decentralized Storj network.`, //****Change connector name here****
+	// Errors are printed by Execute, which also picks the exit code.
+	SilenceErrors: true,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Interrupting the process cancels the context passed to the commands.
 func Execute() {
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	signal.Stop(signals)
+	cancel()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitCode(err))
 	}
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringP("config", "c", "", "combined configuration file with storj and source sections, used instead of the storj and local files.")
+	rootCmd.PersistentFlags().Bool("show-secrets", false, "print API keys, passphrases and other secrets of the configuration instead of redacting them.")
+}
+
+// configFile returns the combined configuration file if one is given
+// and the file of the named flag otherwise.
+func configFile(cmd *cobra.Command, flag string) string {
+	if combined, _ := cmd.Flags().GetString("config"); combined != "" {
+		return combined
+	}
+	fileName, _ := cmd.Flags().GetString(flag)
+	return fileName
+}
+
+// printStorjConfig prints the Storj configuration read from fileName to out,
+// redacting secrets unless --show-secrets is set.
+func printStorjConfig(cmd *cobra.Command, out io.Writer, fileName string, config connector.ConfigStorj) {
+	title := "Storj"
+	if profileName, _ := cmd.Flags().GetString("profile-name"); profileName != "" {
+		title += " profile " + profileName
+	}
+	showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+	connector.PrintConfig(out, title, fileName, config, showSecrets)
+}
+
+// printSourceConfig prints the configuration of src read from fileName if
+// the source exposes it, redacting secrets unless --show-secrets is set.
+func printSourceConfig(cmd *cobra.Command, name string, fileName string, src source.Source) {
+	describer, ok := src.(source.Describer)
+	if !ok {
+		fmt.Println("Read", name, "source configuration from the", fileName, "file.")
+		return
+	}
+	showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+	connector.PrintConfig(os.Stdout, name+" source", fileName, describer.Describe(), showSecrets)
 }
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"storj.io/uplink"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/connector"
+)
+
+// shareCmd represents the share command.
+var shareCmd = &cobra.Command{
+	Use:   "share",
+	Short: "Command to share backups stored on storjV3 network.",
+	Long: `Command to create a restricted serialized access to an existing backup object or prefix under the upload path of given Storj Bucket.
+Permissions and expiry are given as flags; by default the access only allows downloading and listing.`,
+	Args: cobra.NoArgs,
+	RunE: storjShare,
+}
+
+func init() {
+
+	// Setup the share command with its flags.
+	rootCmd.AddCommand(shareCmd)
+	var defaultStorjFile string
+	shareCmd.Flags().BoolP("accesskey", "a", false, "Connect to storj using access key(default connection method is by using API Key).")
+	shareCmd.Flags().BoolP("debug", "d", false, "Collect simple code stat: time & memory alloc & stack")
+	shareCmd.Flags().StringVarP(&defaultStorjFile, "storj", "u", "././config/storj_config.json", "full filepath contaning Storj V3 configuration.")
+	shareCmd.Flags().String("profile-name", "", "name of the profile of the Storj configuration to use (default the defaultProfile of the configuration).")
+	shareCmd.Flags().StringP("key", "k", "", "object key or prefix, relative to the upload path, to share (default shares the whole upload path).")
+	shareCmd.Flags().Bool("download", true, "allow downloading the shared objects.")
+	shareCmd.Flags().Bool("upload", false, "allow uploading objects below the shared prefix.")
+	shareCmd.Flags().Bool("list", true, "allow listing the shared objects.")
+	shareCmd.Flags().Bool("delete", false, "allow deleting the shared objects.")
+	shareCmd.Flags().String("not-before", "", "time the access becomes valid, as an RFC3339 time or relative to now, e.g. `+1h`.")
+	shareCmd.Flags().String("not-after", "", "time the access expires, as an RFC3339 time or relative to now, e.g. `+720h` (default never expires).")
+	shareCmd.Flags().Bool("url", false, "also print a linksharing URL of the shared backup, which embeds the access.")
+	shareCmd.Flags().String("linkshare", connector.DefaultLinkshareURL, "base URL of the linksharing service used by --url.")
+	shareCmd.Flags().Bool("json", false, "print the shared access as JSON.")
+}
+
+// sharedBackup is the printable description of a shared access.
+type sharedBackup struct {
+	*connector.SharedAccess
+	URL string `json:"url,omitempty"`
+}
+
+func storjShare(cmd *cobra.Command, args []string) error {
+
+	// Process arguments from the CLI.
+	fullFileNameStorj := configFile(cmd, "storj")
+	profileName, _ := cmd.Flags().GetString("profile-name")
+	useAccessKey, _ := cmd.Flags().GetBool("accesskey")
+	key, _ := cmd.Flags().GetString("key")
+	withURL, _ := cmd.Flags().GetBool("url")
+	linkshare, _ := cmd.Flags().GetString("linkshare")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	useDebug, _ = cmd.Flags().GetBool("debug")
+
+	var permission uplink.Permission
+	permission.AllowDownload, _ = cmd.Flags().GetBool("download")
+	permission.AllowUpload, _ = cmd.Flags().GetBool("upload")
+	permission.AllowList, _ = cmd.Flags().GetBool("list")
+	permission.AllowDelete, _ = cmd.Flags().GetBool("delete")
+	var notBefore, notAfter connector.Time
+	notBeforeText, _ := cmd.Flags().GetString("not-before")
+	if err := notBefore.UnmarshalText([]byte(notBeforeText)); err != nil {
+		return fmt.Errorf("invalid --not-before: %w", err)
+	}
+	notAfterText, _ := cmd.Flags().GetString("not-after")
+	if err := notAfter.UnmarshalText([]byte(notAfterText)); err != nil {
+		return fmt.Errorf("invalid --not-after: %w", err)
+	}
+	permission.NotBefore = notBefore.Time
+	permission.NotAfter = notAfter.Time
+	if !permission.NotBefore.IsZero() && !permission.NotAfter.IsZero() && !permission.NotAfter.After(permission.NotBefore) {
+		return errors.New("invalid --not-after: not after --not-before")
+	}
+	cmd.SilenceUsage = true
+	ctx := connector.WithTracer(cmd.Context(), traceMetric)
+
+	// Progress output of the configuration and connection steps would
+	// be mistaken for the result by scripts, so send it to stderr instead.
+	out, progress := cmd.OutOrStdout(), cmd.ErrOrStderr()
+	ctx = connector.WithProgress(ctx, progress)
+
+	defer func() {
+		if useDebug {
+			err := saveCollectedMetrics(progress, collectedMetrics)
+			if err != nil {
+				fmt.Fprintf(progress, "failed to save metrics %s\n", err)
+			}
+		}
+	}()
+
+	// Read storj network configurations from and external file and create a storj configuration object.
+	storjConfig, err := connector.LoadStorjProfile(ctx, fullFileNameStorj, profileName)
+	if err != nil {
+		return err
+	}
+	printStorjConfig(cmd, progress, fullFileNameStorj, storjConfig)
+
+	// Connect to storj network using the specified credentials.
+	session, err := connector.OpenSession(ctx, storjConfig, useAccessKey)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := session.Close(); err != nil {
+			fmt.Fprintf(progress, "failed to close session %s", err)
+		}
+	}()
+
+	shared, err := connector.ShareBackup(ctx, session.Project, session.Access, storjConfig, key, permission)
+	if err != nil {
+		return err
+	}
+
+	result := sharedBackup{SharedAccess: shared}
+	if withURL {
+		result.URL = shared.LinkshareURL(linkshare)
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	return printShare(out, result)
+}
+
+// printShare prints the shared access as plain text, one field per line.
+func printShare(out io.Writer, shared sharedBackup) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(writer, "Shared\t: sj://%s/%s\n", shared.Bucket, shared.Prefix)
+	fmt.Fprintf(writer, "Download\t: %t\n", shared.AllowDownload)
+	fmt.Fprintf(writer, "Upload\t: %t\n", shared.AllowUpload)
+	fmt.Fprintf(writer, "List\t: %t\n", shared.AllowList)
+	fmt.Fprintf(writer, "Delete\t: %t\n", shared.AllowDelete)
+	if !shared.NotBefore.IsZero() {
+		fmt.Fprintf(writer, "Not before\t: %s\n", shared.NotBefore)
+	}
+	if !shared.NotAfter.IsZero() {
+		fmt.Fprintf(writer, "Not after\t: %s\n", shared.NotAfter)
+	}
+	fmt.Fprintf(writer, "Access\t: %s\n", shared.Access)
+	if shared.URL != "" {
+		fmt.Fprintf(writer, "URL\t: %s\n", shared.URL)
+	}
+	return writer.Flush()
+}
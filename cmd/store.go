@@ -2,45 +2,60 @@ package cmd
 
 import (
 	"fmt"
+
 	"github.com/pkg/profile"
 	"github.com/spf13/cobra"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/connector"
+	"github.com/storj-thirdparty/connector-framework/pkg/source/local"
 )
 
 // storeCmd represents the store command.
 var storeCmd = &cobra.Command{
 	Use:   "store",
 	Short: "Command to upload data to storjV3 network.",
-	Long:  `Command to connect and uplaod local file to given Storj Bucket.`, // ****Change the description here****
-	Run:   localStore,                                                        //****Change the name by replacing `local` with the name of the source being used****
+	Long:  `Command to connect to the selected source and upload its data to given Storj Bucket.`,
+	RunE:  localStore,
 }
 
 func init() {
 
 	// Setup the store command with its flags.
 	rootCmd.AddCommand(storeCmd)
-	var defaultLocalFile string //****Change the variable name to store the default configuration file location****
+	var defaultLocalFile string
+	var defaultSource string
 	var defaultStorjFile string
 	var prof string
 	storeCmd.Flags().BoolP("accesskey", "a", false, "Connect to storj using access key(default connection method is by using API Key).")
 	storeCmd.Flags().BoolP("share", "s", false, "For generating share access of the uploaded backup file.")
+	storeCmd.Flags().Bool("prune", false, "Prune old backups using the configured retention policy after the upload.")
+	storeCmd.Flags().IntP("workers", "w", connector.DefaultWorkers, "Number of items uploaded in parallel (overrides workers of the Storj configuration).")
 	storeCmd.Flags().BoolP("debug", "d", false, "Collect simple code stat: time & memory alloc & stack")
 	storeCmd.Flags().StringVarP(&prof, "profile", "p", "", "Enable pprof. pprof is disabled by default. Options: `cpu`, `memory`, `block`, `goroutine`")
-	storeCmd.Flags().StringVarP(&defaultLocalFile, "local", "l", "././config/local.json", "full filepath contaning local file path.") //****Change the flag name and its description****
+	storeCmd.Flags().StringVar(&defaultSource, "source", local.Name, "name of the registered source to back up from.")
+	storeCmd.Flags().StringVarP(&defaultLocalFile, "local", "l", "././config/local.json", "full filepath contaning source configuration.")
 	storeCmd.Flags().StringVarP(&defaultStorjFile, "storj", "u", "././config/storj_config.json", "full filepath contaning Storj V3 configuration.")
+	storeCmd.Flags().String("profile-name", "", "name of the profile of the Storj configuration to use (default the defaultProfile of the configuration).")
 }
 
 var useDebug bool
 var collectedMetrics []*Metric
 
-func localStore(cmd *cobra.Command, args []string) {
+func localStore(cmd *cobra.Command, args []string) error {
 
 	// Process arguments from the CLI.
-	localConfigFilePath, _ := cmd.Flags().GetString("local") //****Change the command argument here****
-	fullFileNameStorj, _ := cmd.Flags().GetString("storj")
+	sourceName, _ := cmd.Flags().GetString("source")
+	sourceConfigFilePath := configFile(cmd, "local")
+	fullFileNameStorj := configFile(cmd, "storj")
+	profileName, _ := cmd.Flags().GetString("profile-name")
 	profiling, _ := cmd.Flags().GetString("profile")
 	useAccessKey, _ := cmd.Flags().GetBool("accesskey")
 	useAccessShare, _ := cmd.Flags().GetBool("share")
+	usePrune, _ := cmd.Flags().GetBool("prune")
+	workers, _ := cmd.Flags().GetInt("workers")
 	useDebug, _ = cmd.Flags().GetBool("debug")
+	cmd.SilenceUsage = true
+	ctx := connector.WithTracer(cmd.Context(), traceMetric)
 
 	if profiling == "cpu" {
 		defer profile.Start(profile.CPUProfile, profile.ProfilePath("./profile")).Stop()
@@ -60,38 +75,45 @@ func localStore(cmd *cobra.Command, args []string) {
 
 	defer func() {
 		if useDebug {
-			err := saveCollectedMetrics(collectedMetrics)
+			err := saveCollectedMetrics(cmd.OutOrStdout(), collectedMetrics)
 			if err != nil {
-				fmt.Printf("failed to save metrcis %s", err)
+				fmt.Printf("failed to save metrics %s\n", err)
 			}
 		}
 	}()
 
-	// Read local file configuration from an external file and create a configuration object.
-	//****Change the statement as per the `source` code Function****
-	configLocalFile := LoadLocalProperty(localConfigFilePath)
+	// Create the selected source and configure it from an external file.
+	src, err := connector.LoadSource(ctx, sourceName, sourceConfigFilePath)
+	if err != nil {
+		return err
+	}
+	printSourceConfig(cmd, sourceName, sourceConfigFilePath, src)
+	defer func() {
+		if err := src.Close(); err != nil {
+			fmt.Printf("failed to close source %s", err)
+		}
+	}()
 
 	// Read storj network configurations from and external file and create a storj configuration object.
-	storjConfig := LoadStorjConfiguration(fullFileNameStorj)
-
-	// Connect to storj network using the specified credentials.
-	access, project := ConnectToStorj(storjConfig, useAccessKey)
-
-	// Retrieve the reader of the specified file.
-	//****This will store the file(s)/reader to be uplaoded****
-	reader := ConnectToLocalDisk(configLocalFile)
+	storjConfig, err := connector.LoadStorjProfile(ctx, fullFileNameStorj, profileName)
+	if err != nil {
+		return err
+	}
+	printStorjConfig(cmd, cmd.OutOrStdout(), fullFileNameStorj, storjConfig)
 
-	fmt.Printf("Initiating back-up.\n")
-	// Upload the desired file to desired Storj bucket.
-	//****Change this code fragment by adding a loop if more than one file are to be uploaded
-	//    and also process the file name to be uplaoded to a standard form(if required)****
-	UploadData(project, storjConfig, configLocalFile.Path, reader)
-	fmt.Printf("Back-up complete.\n\n")
+	if cmd.Flags().Changed("workers") {
+		storjConfig.Workers = workers
+	}
 
-	// Create restricted shareable serialized access if share is provided as argument.
-	if useAccessShare {
-		ShareAccess(access, storjConfig)
+	runner := connector.Runner{
+		Config:       storjConfig,
+		UseAccessKey: useAccessKey,
+		SourceName:   sourceName,
+		Source:       src,
+		Prune:        usePrune,
+		Share:        useAccessShare,
 	}
+	return runner.Run(ctx)
 }
 
 func bToMb(b uint64) uint64 {
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/connector"
+)
+
+// verifyCmd represents the verify command.
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Command to verify backups stored on storjV3 network.",
+	Long: `Command to download backups stored under the upload path of given Storj Bucket and compare the SHA-256 checksum and size of their data with the ones recorded when uploading.
+Backups uploaded without a checksum are skipped.`,
+	Args: cobra.NoArgs,
+	RunE: storjVerify,
+}
+
+func init() {
+
+	// Setup the verify command with its flags.
+	rootCmd.AddCommand(verifyCmd)
+	var defaultStorjFile string
+	verifyCmd.Flags().BoolP("accesskey", "a", false, "Connect to storj using access key(default connection method is by using API Key).")
+	verifyCmd.Flags().BoolP("debug", "d", false, "Collect simple code stat: time & memory alloc & stack")
+	verifyCmd.Flags().StringVarP(&defaultStorjFile, "storj", "u", "././config/storj_config.json", "full filepath contaning Storj V3 configuration.")
+	verifyCmd.Flags().String("profile-name", "", "name of the profile of the Storj configuration to use (default the defaultProfile of the configuration).")
+	verifyCmd.Flags().StringP("key", "k", "", "object key or prefix, relative to the upload path, to verify (default verifies everything).")
+	verifyCmd.Flags().String("run", "", "verify the backups uploaded by the run with the given ID against the checksums of its manifest.")
+}
+
+func storjVerify(cmd *cobra.Command, args []string) error {
+
+	// Process arguments from the CLI.
+	fullFileNameStorj := configFile(cmd, "storj")
+	profileName, _ := cmd.Flags().GetString("profile-name")
+	useAccessKey, _ := cmd.Flags().GetBool("accesskey")
+	key, _ := cmd.Flags().GetString("key")
+	runID, _ := cmd.Flags().GetString("run")
+	if key != "" && runID != "" {
+		return errors.New("--key and --run cannot be combined")
+	}
+	useDebug, _ = cmd.Flags().GetBool("debug")
+	cmd.SilenceUsage = true
+	ctx := connector.WithTracer(cmd.Context(), traceMetric)
+
+	defer func() {
+		if useDebug {
+			err := saveCollectedMetrics(cmd.OutOrStdout(), collectedMetrics)
+			if err != nil {
+				fmt.Printf("failed to save metrics %s\n", err)
+			}
+		}
+	}()
+
+	// Read storj network configurations from and external file and create a storj configuration object.
+	storjConfig, err := connector.LoadStorjProfile(ctx, fullFileNameStorj, profileName)
+	if err != nil {
+		return err
+	}
+	printStorjConfig(cmd, cmd.OutOrStdout(), fullFileNameStorj, storjConfig)
+
+	// Connect to storj network using the specified credentials.
+	session, err := connector.OpenSession(ctx, storjConfig, useAccessKey)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := session.Close(); err != nil {
+			fmt.Printf("failed to close session %s", err)
+		}
+	}()
+
+	fmt.Printf("Initiating verification.\n")
+	var report *connector.VerifyReport
+	if runID != "" {
+		report, err = connector.VerifyRun(ctx, session.Project, storjConfig, runID)
+	} else {
+		report, err = connector.VerifyBackups(ctx, session.Project, storjConfig, key)
+	}
+	if report != nil {
+		report.Print(cmd.OutOrStdout())
+	}
+	if err != nil {
+		return err
+	}
+	return report.Err()
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/connector"
 )
 
 // versionCmd represents the version command
@@ -12,7 +14,7 @@ var versionCmd = &cobra.Command{
 	Short: "Prints the version of the cli",
 	Long:  `Prints the version of the cli`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Version 1.0.7")
+		fmt.Println("Version " + connector.Version)
 	},
 }
 
@@ -3,11 +3,18 @@ module github.com/storj-thirdparty/connector-framework
 go 1.13
 
 require (
-	github.com/google/uuid v1.2.0 // indirect
-	github.com/minio/sha256-simd v0.1.1 // indirect
-	github.com/pkg/profile v1.5.0 // indirect
+	filippo.io/age v1.0.0
+	github.com/BurntSushi/toml v0.4.1
+	github.com/google/uuid v1.2.0
+	github.com/klauspost/compress v1.11.13
+	github.com/minio/sha256-simd v0.1.1
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pkg/profile v1.5.0
 	github.com/spacemonkeygo/errors v0.0.0-20171212215202-9064522e9fd1 // indirect
 	github.com/spf13/cobra v1.0.0
-	golang.org/x/sys v0.0.0-20200625212154-ddb9806d33ae // indirect
-	storj.io/uplink v1.4.5
+	github.com/zeebo/errs v1.2.2
+	go.uber.org/multierr v1.6.0 // indirect
+	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5
+	gopkg.in/yaml.v2 v2.4.0
+	storj.io/uplink v1.6.0
 )
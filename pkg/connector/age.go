@@ -0,0 +1,90 @@
+package connector
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// AgeEncryption is the name of the transform encrypting data with age to the
+// encryptionRecipients of the configuration.
+const AgeEncryption = "age"
+
+// MetadataAgeRecipients is the custom metadata key listing the fingerprints
+// of the age recipients an object is encrypted to, comma separated.
+const MetadataAgeRecipients = "age-recipients"
+
+func init() {
+	RegisterTransform(AgeEncryption, newAgeTransform)
+}
+
+// ageTransform encrypts data to age X25519 recipients. Decrypting requires
+// the identity file, which is only read when restoring.
+type ageTransform struct {
+	recipients   []age.Recipient
+	fingerprints []string
+	identityFile string
+}
+
+func newAgeTransform(configStorj ConfigStorj) (Transform, error) {
+	transform := &ageTransform{identityFile: configStorj.EncryptionIdentityFile}
+	for _, recipient := range configStorj.EncryptionRecipients {
+		parsed, err := age.ParseX25519Recipient(recipient)
+		if err != nil {
+			return nil, err
+		}
+		transform.recipients = append(transform.recipients, parsed)
+		transform.fingerprints = append(transform.fingerprints, AgeFingerprint(parsed.String()))
+	}
+	return transform, nil
+}
+
+// AgeFingerprint returns the fingerprint of an age recipient recorded in the
+// custom metadata, which identifies the key without disclosing it.
+func AgeFingerprint(recipient string) string {
+	sum := sha256.Sum256([]byte(recipient))
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+func (*ageTransform) Name() string { return AgeEncryption }
+
+func (transform *ageTransform) Metadata() map[string]string {
+	return map[string]string{MetadataAgeRecipients: strings.Join(transform.fingerprints, ",")}
+}
+
+func (transform *ageTransform) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	if len(transform.recipients) == 0 {
+		return nil, fmt.Errorf("%s: no encryptionRecipients configured", AgeEncryption)
+	}
+	return age.Encrypt(w, transform.recipients...)
+}
+
+func (transform *ageTransform) NewReader(r io.Reader) (io.ReadCloser, error) {
+	if transform.identityFile == "" {
+		return nil, fmt.Errorf("%s: the data is encrypted, but no encryptionIdentityFile is configured", AgeEncryption)
+	}
+	file, err := os.Open(filepath.Clean(transform.identityFile))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", AgeEncryption, err)
+	}
+	identities, err := age.ParseIdentities(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", AgeEncryption, transform.identityFile, err)
+	}
+
+	decrypted, err := age.Decrypt(r, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", AgeEncryption, err)
+	}
+	return ioutil.NopCloser(decrypted), nil
+}
@@ -0,0 +1,77 @@
+package connector
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+
+	"github.com/minio/sha256-simd"
+	"storj.io/uplink"
+)
+
+// Custom metadata keys recording the SHA-256 checksum, hex encoded, and the
+// size of the original data of an object, before any transform.
+const (
+	MetadataSHA256 = "sha256"
+	MetadataSize   = "size"
+)
+
+// Checksum identifies the original data of an object.
+type Checksum struct {
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// IsZero reports whether no checksum is recorded.
+func (checksum Checksum) IsZero() bool {
+	return checksum.SHA256 == ""
+}
+
+// Metadata returns the custom metadata recording the checksum.
+func (checksum Checksum) Metadata() uplink.CustomMetadata {
+	return uplink.CustomMetadata{
+		MetadataSHA256: checksum.SHA256,
+		MetadataSize:   strconv.FormatInt(checksum.Size, 10),
+	}
+}
+
+// ChecksumFromMetadata returns the checksum recorded in the custom metadata
+// of an object, or a zero Checksum if none is recorded.
+func ChecksumFromMetadata(metadata uplink.CustomMetadata) (Checksum, error) {
+	checksum := Checksum{SHA256: metadata[MetadataSHA256]}
+	if checksum.SHA256 == "" {
+		return Checksum{}, nil
+	}
+	size, err := strconv.ParseInt(metadata[MetadataSize], 10, 64)
+	if err != nil {
+		return Checksum{}, fmt.Errorf("invalid %s metadata: %w", MetadataSize, err)
+	}
+	checksum.Size = size
+	return checksum, nil
+}
+
+// checksumWriter computes the checksum of the data written through it.
+type checksumWriter struct {
+	w    io.Writer
+	hash hash.Hash
+	size int64
+}
+
+// newChecksumWriter returns a writer computing the checksum of the data written to w.
+func newChecksumWriter(w io.Writer) *checksumWriter {
+	return &checksumWriter{w: w, hash: sha256.New()}
+}
+
+func (writer *checksumWriter) Write(p []byte) (int, error) {
+	n, err := writer.w.Write(p)
+	_, _ = writer.hash.Write(p[:n])
+	writer.size += int64(n)
+	return n, err
+}
+
+// Checksum returns the checksum of the data written so far.
+func (writer *checksumWriter) Checksum() Checksum {
+	return Checksum{SHA256: hex.EncodeToString(writer.hash.Sum(nil)), Size: writer.size}
+}
@@ -0,0 +1,63 @@
+package connector
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"storj.io/uplink"
+)
+
+func TestChecksum(t *testing.T) {
+	writer := newChecksumWriter(ioutil.Discard)
+	for _, part := range []string{"hello", " ", "world"} {
+		if _, err := writer.Write([]byte(part)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checksum := writer.Checksum()
+	want := Checksum{SHA256: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9", Size: 11}
+	if checksum != want {
+		t.Fatalf("got %+v, want %+v", checksum, want)
+	}
+
+	recorded, err := ChecksumFromMetadata(checksum.Metadata())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded != checksum {
+		t.Errorf("got %+v from metadata, want %+v", recorded, checksum)
+	}
+
+	tests := []struct {
+		name     string
+		metadata uplink.CustomMetadata
+		want     Checksum
+		err      bool
+	}{
+		{name: "none", metadata: nil},
+		{name: "transforms only", metadata: uplink.CustomMetadata{MetadataTransforms: "gzip"}},
+		{name: "invalid size", metadata: uplink.CustomMetadata{MetadataSHA256: want.SHA256, MetadataSize: "x"}, err: true},
+	}
+	for _, test := range tests {
+		got, err := ChecksumFromMetadata(test.metadata)
+		if (err != nil) != test.err {
+			t.Errorf("%s: got error %v", test.name, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+
+	if err := compareChecksums(want, want); err != nil {
+		t.Error(err)
+	}
+	truncated := Checksum{SHA256: want.SHA256, Size: 5}
+	if err := compareChecksums(want, truncated); !errors.Is(err, errChecksumMismatch) {
+		t.Errorf("got %v for a truncated object", err)
+	}
+	corrupted := Checksum{SHA256: "00", Size: 11}
+	if err := compareChecksums(want, corrupted); !errors.Is(err, errChecksumMismatch) {
+		t.Errorf("got %v for a corrupted object", err)
+	}
+}
@@ -0,0 +1,240 @@
+package connector
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// StorjEnvPrefix prefixes the environment variables overriding ConfigStorj fields,
+// e.g. STORJ_APIKEY or STORJ_RETENTION_KEEP_LAST.
+const StorjEnvPrefix = "STORJ_"
+
+// SourceEnvPrefix returns the prefix of the environment variables overriding the
+// configuration of the named source, e.g. SOURCE_LOCAL_ for SOURCE_LOCAL_PATH.
+func SourceEnvPrefix(sourceName string) string {
+	return "SOURCE_" + envName(sourceName) + "_"
+}
+
+// EnvVar is a configuration field that can be overridden from the environment.
+type EnvVar struct {
+	// Path is the JSON path of the field, e.g. retention.keepLast.
+	Path string
+	// Name is the name of the environment variable holding the value.
+	// Name + "_FILE" names a file holding the value instead.
+	Name string
+}
+
+// EnvVars lists the environment variables overriding the fields of config,
+// which must be a struct or a pointer to one.
+func EnvVars(prefix string, config interface{}) []EnvVar {
+	var vars []EnvVar
+	t := reflect.TypeOf(config)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	collectEnvVars(t, prefix, "", &vars)
+	return vars
+}
+
+// collectEnvVars appends a variable for every leaf field of the struct type t.
+func collectEnvVars(t reflect.Type, prefix, path string, vars *[]EnvVar) {
+	if t == nil || t.Kind() != reflect.Struct || isScalar(t) {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		if field.Type.Kind() == reflect.Struct && !isScalar(field.Type) {
+			collectEnvVars(field.Type, prefix, fieldPath, vars)
+			continue
+		}
+		*vars = append(*vars, EnvVar{
+			Path: fieldPath,
+			Name: prefix + envName(fieldPath),
+		})
+	}
+}
+
+// isScalar reports whether values of the struct type t are decoded as a whole,
+// like time.Time, rather than field by field.
+func isScalar(t reflect.Type) bool {
+	ptr := reflect.PtrTo(t)
+	return ptr.Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) ||
+		ptr.Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem())
+}
+
+// envName converts a JSON path to the upper snake case used in
+// environment variable names, e.g. retention.keepLast to RETENTION_KEEP_LAST.
+func envName(path string) string {
+	var b strings.Builder
+	var prev rune
+	for _, r := range path {
+		switch {
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			b.WriteRune('_')
+			b.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToUpper(r))
+		default:
+			r = '_'
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
+
+// lookupEnvValue returns the value of the variable name, or the contents of the
+// file named by name_FILE with trailing newlines removed.
+// Setting both is an error.
+func lookupEnvValue(lookup func(string) (string, bool), name string) (string, bool, error) {
+	value, ok := lookup(name)
+	fileName, fileOK := lookup(name + "_FILE")
+	switch {
+	case ok && fileOK:
+		return "", false, fmt.Errorf("both %s and %s_FILE are set", name, name)
+	case fileOK:
+		data, err := ioutil.ReadFile(filepath.Clean(fileName))
+		if err != nil {
+			return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	}
+	return value, ok, nil
+}
+
+// applyEnv overrides the fields of the JSON object data with the environment
+// variables listed by EnvVars(prefix, config) that are set, converting every
+// value to the type of its field. Fields missing from data are added.
+func applyEnv(data []byte, prefix string, config interface{}) ([]byte, error) {
+	return applyOverrides(data, prefix, config, os.LookupEnv)
+}
+
+// applyOverrides is applyEnv reading the variables through lookup.
+func applyOverrides(data []byte, prefix string, config interface{}, lookup func(string) (string, bool)) ([]byte, error) {
+	object := make(map[string]interface{})
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	changed := false
+	for _, v := range EnvVars(prefix, config) {
+		value, ok, err := lookupEnvValue(lookup, v.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		field, err := envFieldValue(config, v.Path, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.Name, err)
+		}
+		setPath(object, strings.Split(v.Path, "."), field)
+		changed = true
+	}
+
+	if !changed {
+		return data, nil
+	}
+	return json.Marshal(object)
+}
+
+// envFieldValue converts value to the JSON encoding of the field at path of config.
+// Strings are taken verbatim; other types are decoded as text or JSON, and
+// string lists may also be given comma separated.
+func envFieldValue(config interface{}, path string, value string) (json.RawMessage, error) {
+	t := reflect.TypeOf(config)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for _, name := range strings.Split(path, ".") {
+		field, ok := fieldByJSONName(t, name)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %s", path)
+		}
+		t = field.Type
+	}
+
+	target := reflect.New(t)
+	switch unmarshaler := target.Interface().(type) {
+	case encoding.TextUnmarshaler:
+		if err := unmarshaler.UnmarshalText([]byte(value)); err != nil {
+			return nil, err
+		}
+	default:
+		if t.Kind() == reflect.String {
+			target.Elem().SetString(value)
+			break
+		}
+		if err := json.Unmarshal([]byte(value), target.Interface()); err != nil {
+			if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.String {
+				return nil, err
+			}
+			target.Elem().Set(reflect.ValueOf(strings.Split(value, ",")).Convert(t))
+		}
+	}
+	return json.Marshal(target.Interface())
+}
+
+// fieldByJSONName returns the field of the struct type t encoded under name.
+func fieldByJSONName(t reflect.Type, name string) (reflect.StructField, bool) {
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == name || (tag == "" && field.Name == name) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// setPath sets the value at path in object, creating intermediate objects.
+// JSON keys match struct fields case-insensitively, so keys differing only
+// in case are replaced.
+func setPath(object map[string]interface{}, path []string, value json.RawMessage) {
+	key := path[0]
+	var existing interface{}
+	for k, v := range object {
+		if strings.EqualFold(k, key) {
+			existing = v
+			delete(object, k)
+		}
+	}
+
+	if len(path) == 1 {
+		object[key] = value
+		return
+	}
+
+	child, ok := existing.(map[string]interface{})
+	if !ok {
+		child = make(map[string]interface{})
+	}
+	setPath(child, path[1:], value)
+	object[key] = child
+}
@@ -0,0 +1,77 @@
+package connector
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/source/local"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	dir, err := ioutil.TempDir("", "connector-env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	secretFile := filepath.Join(dir, "apikey")
+	if err := ioutil.WriteFile(secretFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{
+		"STORJ_APIKEY_FILE":          secretFile,
+		"STORJ_BUCKET":               "from-env",
+		"STORJ_WORKERS":              "8",
+		"STORJ_RETENTION_KEEP_LAST":  "3",
+		"STORJ_UPLOAD_PATH":          "backups",
+		"SOURCE_LOCAL_EXCLUDE":       "*.tmp,*.log",
+		"SOURCE_LOCAL_UNRELATED_VAR": "ignored",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	file := []byte(`{"apiKey": "from-file", "bucket": "from-file", "satellite": "from-file", "retention": {"keepDaily": 7}}`)
+	data, err := applyOverrides(file, StorjEnvPrefix, ConfigStorj{}, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config ConfigStorj
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	want := ConfigStorj{
+		APIKey:     "secret",
+		Satellite:  "from-file",
+		Bucket:     "from-env",
+		UploadPath: "backups",
+		Workers:    8,
+		Retention:  RetentionPolicy{KeepLast: 3, KeepDaily: 7},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("got %+v, want %+v", config, want)
+	}
+
+	data, err = applyOverrides([]byte(`{"path": "/data"}`), SourceEnvPrefix(local.Name), local.Config{}, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var localConfig local.Config
+	if err := json.Unmarshal(data, &localConfig); err != nil {
+		t.Fatal(err)
+	}
+	if localConfig.Path != "/data" || len(localConfig.Exclude) != 2 || localConfig.Exclude[1] != "*.log" {
+		t.Errorf("got %+v", localConfig)
+	}
+
+	env["STORJ_APIKEY"] = "both"
+	if _, err := applyOverrides(file, StorjEnvPrefix, ConfigStorj{}, lookup); err == nil {
+		t.Error("expected an error when both STORJ_APIKEY and STORJ_APIKEY_FILE are set")
+	}
+}
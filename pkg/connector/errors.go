@@ -0,0 +1,90 @@
+package connector
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ConfigError is returned when a configuration file cannot be loaded or is invalid.
+type ConfigError struct {
+	Path string
+	Err  error
+}
+
+func (e *ConfigError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("invalid configuration: %v", e.Err)
+	}
+	return fmt.Sprintf("could not load configuration %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ConfigError) Unwrap() error { return e.Err }
+
+// ConnectError is returned when the connection to the Storj network fails.
+type ConnectError struct {
+	Err error
+}
+
+func (e *ConnectError) Error() string {
+	return fmt.Sprintf("could not connect to storj: %v", e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ConnectError) Unwrap() error { return e.Err }
+
+// SourceError is returned when a source fails to configure, list or open its items.
+type SourceError struct {
+	Source string
+	Err    error
+}
+
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("source %s: %v", e.Source, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *SourceError) Unwrap() error { return e.Err }
+
+// TransferError is returned when an operation on an object fails.
+// Op is one of "upload", "download", "list", "delete", "share" or "verify".
+type TransferError struct {
+	Op  string
+	Key string
+	Err error
+}
+
+func (e *TransferError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("could not %s: %v", e.Op, e.Err)
+	}
+	return fmt.Sprintf("could not %s %s: %v", e.Op, e.Key, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *TransferError) Unwrap() error { return e.Err }
+
+// errSizeMismatch is the error of a CopyError when the data of an item
+// is shorter or longer than its expected size.
+var errSizeMismatch = errors.New("size differs from the expected size")
+
+// CopyError is wrapped by a TransferError when copying the data of an upload fails.
+// Op is "read" if reading the source data failed and "write" if writing to the upload failed.
+type CopyError struct {
+	Op string
+	// Written is the number of bytes written before the failure.
+	Written int64
+	// Expected is the expected size of the data, or -1 if unknown.
+	Expected int64
+	Err      error
+}
+
+func (e *CopyError) Error() string {
+	if e.Expected >= 0 {
+		return fmt.Sprintf("%s failed after %d of %d bytes: %v", e.Op, e.Written, e.Expected, e.Err)
+	}
+	return fmt.Sprintf("%s failed after %d bytes: %v", e.Op, e.Written, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *CopyError) Unwrap() error { return e.Err }
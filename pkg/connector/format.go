@@ -0,0 +1,135 @@
+package connector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Sections of a combined configuration file holding both
+// the Storj and the source configuration.
+const (
+	StorjSection  = "storj"
+	SourceSection = "source"
+)
+
+// readConfigFile reads a configuration file and returns its contents as JSON.
+// Files ending in .yaml, .yml or .toml are converted; any other file is JSON.
+func readConfigFile(fullFileName string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(fullFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	var document interface{}
+	switch strings.ToLower(filepath.Ext(fullFileName)) {
+	case ".yaml", ".yml":
+		if err = yaml.Unmarshal(data, &document); err != nil {
+			return nil, err
+		}
+		document = yamlToJSON(document)
+	case ".toml":
+		var table map[string]interface{}
+		if _, err = toml.Decode(string(data), &table); err != nil {
+			return nil, err
+		}
+		document = table
+	default:
+		return data, nil
+	}
+
+	return json.Marshal(document)
+}
+
+// WriteConfigFile writes config as JSON, YAML or TOML, chosen by the extension
+// of fullFileName like when reading it. The file is only readable by its owner
+// as it holds credentials, and an existing file is replaced.
+func WriteConfigFile(fullFileName string, config interface{}) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return &ConfigError{Path: fullFileName, Err: err}
+	}
+
+	var document map[string]interface{}
+	switch strings.ToLower(filepath.Ext(fullFileName)) {
+	case ".yaml", ".yml":
+		if err = json.Unmarshal(data, &document); err == nil {
+			data, err = yaml.Marshal(document)
+		}
+	case ".toml":
+		if err = json.Unmarshal(data, &document); err == nil {
+			var buf bytes.Buffer
+			err = toml.NewEncoder(&buf).Encode(document)
+			data = buf.Bytes()
+		}
+	default:
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return &ConfigError{Path: fullFileName, Err: err}
+	}
+
+	fullFileName = filepath.Clean(fullFileName)
+	if err = os.MkdirAll(filepath.Dir(fullFileName), 0700); err != nil {
+		return &ConfigError{Path: fullFileName, Err: err}
+	}
+	file, err := os.OpenFile(fullFileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return &ConfigError{Path: fullFileName, Err: err}
+	}
+	// Tighten the permissions of a file that already existed.
+	err = file.Chmod(0600)
+	if err == nil {
+		_, err = file.Write(data)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return &ConfigError{Path: fullFileName, Err: err}
+	}
+	return nil
+}
+
+// yamlToJSON replaces the map[interface{}]interface{} values decoded from YAML
+// by map[string]interface{}, which can be encoded as JSON.
+func yamlToJSON(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			object[fmt.Sprint(k)] = yamlToJSON(v)
+		}
+		return object
+	case []interface{}:
+		for i, v := range value {
+			value[i] = yamlToJSON(v)
+		}
+	}
+	return value
+}
+
+// configSection returns the named section of a combined configuration file.
+// A file without a StorjSection is not combined and is returned as is.
+func configSection(data []byte, name string) ([]byte, error) {
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if _, combined := document[StorjSection]; !combined {
+		return data, nil
+	}
+
+	section, ok := document[name]
+	if !ok {
+		return nil, fmt.Errorf("missing %q section", name)
+	}
+	return section, nil
+}
@@ -0,0 +1,93 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "connector-format")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	files := map[string]string{
+		"storj.json":   `{"bucket": "backups", "retention": {"keepLast": 3}}`,
+		"storj.yaml":   "# comment\nbucket: backups\nretention:\n  keepLast: 3\n",
+		"storj.toml":   "# comment\nbucket = \"backups\"\n[retention]\nkeepLast = 3\n",
+		"combined.yml": "storj:\n  bucket: backups\n  retention:\n    keepLast: 3\nsource:\n  path: /data\n",
+	}
+
+	want := ConfigStorj{Bucket: "backups", Retention: RetentionPolicy{KeepLast: 3}}
+	for name, contents := range files {
+		fileName := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(fileName, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := readConfigFile(fileName)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		data, err = configSection(data, StorjSection)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		var config ConfigStorj
+		if err := json.Unmarshal(data, &config); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(config, want) {
+			t.Errorf("%s: got %+v, want %+v", name, config, want)
+		}
+	}
+}
+
+func TestWriteConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "connector-format")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	config := ConfigStorj{
+		APIKey:               "key",
+		Satellite:            "us1.storj.io:7777",
+		Bucket:               "backups",
+		UploadPath:           "db01/",
+		EncryptionPassphrase: "passphrase",
+		AllowDownload:        true,
+		Workers:              DefaultWorkers,
+		Retention:            RetentionPolicy{KeepDaily: 7},
+	}
+
+	for _, name := range []string{"storj.json", "storj.yaml", "storj.toml"} {
+		fileName := filepath.Join(dir, "config", name)
+		if err := WriteConfigFile(fileName, config); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		info, err := os.Stat(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("%s: got mode %v, want 0600", name, mode)
+		}
+
+		loaded, err := LoadStorjConfiguration(context.Background(), fileName)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(loaded, config) {
+			t.Errorf("%s: got %+v, want %+v", name, loaded, config)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package connector
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultKeyTemplate stores every item under its path relative to the source.
+const DefaultKeyTemplate = "{{.RelPath}}"
+
+// Run identifies a single execution of the store command.
+type Run struct {
+	ID      string
+	Started time.Time
+	Host    string
+	Source  string
+}
+
+// NewRun creates a run for the given source, started now.
+func NewRun(sourceName string) Run {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return Run{
+		ID:      uuid.New().String(),
+		Started: time.Now().UTC(),
+		Host:    host,
+		Source:  sourceName,
+	}
+}
+
+// KeyData holds the values available to the object key template.
+type KeyData struct {
+	// Date is the run start date, e.g. 2021-03-31.
+	Date string
+	// Time is the run start time of day, e.g. 150405.
+	Time string
+	// Timestamp is the sortable run start time, e.g. 20210331T150405Z.
+	Timestamp string
+	// Unix is the run start time in seconds since the epoch.
+	Unix int64
+	// Host is the hostname of the machine running the backup.
+	Host string
+	// RunID is the unique identifier of the run.
+	RunID string
+	// Source is the name of the source being backed up.
+	Source string
+	// RelPath is the slash separated path of the item relative to the source.
+	RelPath string
+	// Base is the last element of RelPath.
+	Base string
+}
+
+// KeyTemplate renders object keys, relative to the upload path, for source items.
+type KeyTemplate struct {
+	tmpl *template.Template
+}
+
+// ParseKeyTemplate parses the object key template.
+// An empty text uses DefaultKeyTemplate.
+func ParseKeyTemplate(text string) (*KeyTemplate, error) {
+	if text == "" {
+		text = DefaultKeyTemplate
+	}
+	tmpl, err := template.New("key").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key template: %w", err)
+	}
+	return &KeyTemplate{tmpl: tmpl}, nil
+}
+
+// runFields are the key data fields that are the same for every item of a run.
+// At least one of Date, Time, Timestamp, Unix and RunID identifies the run.
+var runFields = map[string]bool{"Date": true, "Time": true, "Timestamp": true, "Unix": true, "RunID": true, "Host": false, "Source": false}
+
+// GroupsByRun reports whether the first path element of the rendered keys
+// only depends on the run and includes a time or run field, e.g.
+// {{.Timestamp}}/{{.RelPath}}, so that every entry directly below the upload
+// path holds the objects of a single run, or of the runs of the same period.
+func (keyTemplate *KeyTemplate) GroupsByRun() bool {
+	identified := false
+	for i, node := range keyTemplate.tmpl.Tree.Root.Nodes {
+		switch node := node.(type) {
+		case *parse.TextNode:
+			text := string(node.Text)
+			if i == 0 {
+				// Leading slashes are removed from the rendered keys.
+				text = strings.TrimLeft(text, "/")
+			}
+			if strings.Contains(text, "/") {
+				return identified
+			}
+		case *parse.ActionNode:
+			if len(node.Pipe.Decl) != 0 || len(node.Pipe.Cmds) != 1 || len(node.Pipe.Cmds[0].Args) != 1 {
+				return false
+			}
+			field, ok := node.Pipe.Cmds[0].Args[0].(*parse.FieldNode)
+			if !ok || len(field.Ident) != 1 {
+				return false
+			}
+			identifies, sameForRun := runFields[field.Ident[0]]
+			if !sameForRun {
+				return false
+			}
+			identified = identified || identifies
+		default:
+			return false
+		}
+	}
+	// The whole key is a single entry per item.
+	return false
+}
+
+// Key renders the object key of the item stored under relPath during run.
+func (keyTemplate *KeyTemplate) Key(run Run, relPath string) (string, error) {
+	relPath = strings.TrimPrefix(path.Clean("/"+relPath), "/")
+	data := KeyData{
+		Date:      run.Started.Format("2006-01-02"),
+		Time:      run.Started.Format("150405"),
+		Timestamp: run.Started.Format("20060102T150405Z"),
+		Unix:      run.Started.Unix(),
+		Host:      run.Host,
+		RunID:     run.ID,
+		Source:    run.Source,
+		RelPath:   relPath,
+		Base:      path.Base(relPath),
+	}
+
+	var buf bytes.Buffer
+	if err := keyTemplate.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("could not render key template: %w", err)
+	}
+
+	key := strings.TrimPrefix(path.Clean("/"+buf.String()), "/")
+	if key == "" {
+		return "", fmt.Errorf("key template rendered an empty key for %q", relPath)
+	}
+	return key, nil
+}
@@ -0,0 +1,80 @@
+package connector
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyTemplate(t *testing.T) {
+	run := Run{
+		ID:      "0f8fad5b-d9cb-469f-a165-70867728950e",
+		Started: time.Date(2021, 3, 31, 15, 4, 5, 0, time.UTC),
+		Host:    "db01",
+		Source:  "local",
+	}
+
+	tests := []struct {
+		template string
+		relPath  string
+		key      string
+	}{
+		{"", "sub/file.txt", "sub/file.txt"},
+		{"{{.Date}}/{{.Host}}/{{.RelPath}}", "sub/file.txt", "2021-03-31/db01/sub/file.txt"},
+		{"{{.Source}}/{{.Timestamp}}-{{.Base}}", "sub/file.txt", "local/20210331T150405Z-file.txt"},
+		{"{{.RunID}}/{{.RelPath}}", "../../etc/passwd", "0f8fad5b-d9cb-469f-a165-70867728950e/etc/passwd"},
+		{"/{{.Date}}//{{.Time}}/{{.RelPath}}", "file.txt", "2021-03-31/150405/file.txt"},
+	}
+
+	for _, test := range tests {
+		keyTemplate, err := ParseKeyTemplate(test.template)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := keyTemplate.Key(run, test.relPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != test.key {
+			t.Errorf("template %q: got key %q, want %q", test.template, key, test.key)
+		}
+	}
+
+	if _, err := ParseKeyTemplate("{{.Date"); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+	keyTemplate, err := ParseKeyTemplate("{{.Unknown}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keyTemplate.Key(run, "file.txt"); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestKeyTemplateGroupsByRun(t *testing.T) {
+	tests := []struct {
+		template string
+		want     bool
+	}{
+		{"", false},
+		{"{{.RelPath}}", false},
+		{"{{.Host}}/{{.Date}}/{{.RelPath}}", false},
+		{"{{.Date | printf \"%s\"}}/{{.RelPath}}", false},
+		{"/{{.Timestamp}}-{{.Base}}", false},
+		{"{{.Timestamp}}", false},
+		{"{{.Source}}/{{.RelPath}}", false},
+		{"{{.Date}}/{{.Host}}/{{.RelPath}}", true},
+		{"backups-{{.Date}}/{{.RelPath}}", true},
+		{"/{{.Date}}_{{.Time}}/{{.Base}}", true},
+		{"{{.Source}}-{{.RunID}}/{{.RelPath}}", true},
+	}
+	for _, test := range tests {
+		keyTemplate, err := ParseKeyTemplate(test.template)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := keyTemplate.GroupsByRun(); got != test.want {
+			t.Errorf("template %q: got %t, want %t", test.template, got, test.want)
+		}
+	}
+}
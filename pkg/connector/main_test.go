@@ -0,0 +1,53 @@
+package connector_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"testing"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/connector"
+)
+
+func TestMongoStore(t *testing.T) {
+
+	ctx := context.Background()
+	storjConfig, err := connector.LoadStorjConfiguration(ctx, "../../config/storj_config_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := connector.OpenSession(ctx, storjConfig, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := session.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	project := session.Project
+
+	fileReader, err := os.Open("../../testFile.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fmt.Printf("Initiating back-up.\n")
+	if err = connector.UploadData(ctx, project, storjConfig, "testFile.txt", fileReader, -1); err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("Back-up complete.\n\n")
+
+	fmt.Printf("\nDeleting the test back-up.\n")
+	backups := project.ListObjects(ctx, storjConfig.Bucket, nil)
+	// Loop to find the latest back-up of all the back-ups.
+	for backups.Next() {
+		item := backups.Item()
+		_, err := project.DeleteObject(ctx, storjConfig.Bucket, item.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	fmt.Printf("Deleted the test back-up.\n")
+}
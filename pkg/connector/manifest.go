@@ -0,0 +1,213 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeebo/errs"
+	"storj.io/uplink"
+)
+
+// ManifestPrefix is the prefix, relative to the upload path, the manifests
+// of the runs are stored under. Backups are never listed below it.
+const ManifestPrefix = ".manifests/"
+
+// Manifest records the objects uploaded by a single run of the store command.
+type Manifest struct {
+	RunID    string    `json:"runId"`
+	Version  string    `json:"version"`
+	Source   string    `json:"source"`
+	Host     string    `json:"host"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	// Objects are the uploaded objects, sorted by key.
+	Objects []ManifestObject `json:"objects"`
+	// Failed is the number of items that could not be uploaded.
+	Failed int `json:"failed"`
+}
+
+// ManifestObject is an object uploaded by a run.
+type ManifestObject struct {
+	// Key is the full object key, including the upload path.
+	Key string `json:"key"`
+	Checksum
+}
+
+// NewManifest returns the manifest of run, listing the objects uploaded successfully.
+func NewManifest(configStorj ConfigStorj, run Run, report *UploadReport, finished time.Time) *Manifest {
+	manifest := &Manifest{
+		RunID:    run.ID,
+		Version:  Version,
+		Source:   run.Source,
+		Host:     run.Host,
+		Started:  run.Started,
+		Finished: finished.UTC(),
+		Objects:  make([]ManifestObject, 0, len(report.Succeeded)),
+		Failed:   len(report.Failed),
+	}
+	for _, result := range report.Succeeded {
+		manifest.Objects = append(manifest.Objects, ManifestObject{
+			Key:      ObjectKey(configStorj, result.Key),
+			Checksum: result.Checksum,
+		})
+	}
+	sort.Slice(manifest.Objects, func(i, j int) bool {
+		return manifest.Objects[i].Key < manifest.Objects[j].Key
+	})
+	return manifest
+}
+
+// ManifestKey returns the object key of the manifest of the run with the given ID.
+func ManifestKey(configStorj ConfigStorj, runID string) string {
+	return configStorj.UploadPath + ManifestPrefix + runID + ".json"
+}
+
+// isManifestKey reports whether key is stored below the manifest prefix.
+func isManifestKey(configStorj ConfigStorj, key string) bool {
+	return strings.HasPrefix(key, configStorj.UploadPath+ManifestPrefix)
+}
+
+// UploadManifest stores the manifest of a run as a JSON object.
+func UploadManifest(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, manifest *Manifest) error {
+
+	defer trace(ctx, "UploadManifest")()
+
+	key := ManifestKey(configStorj, manifest.RunID)
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return &TransferError{Op: "upload", Key: key, Err: err}
+	}
+
+	upload, err := project.UploadObject(ctx, configStorj.Bucket, key, nil)
+	if err != nil {
+		return &TransferError{Op: "upload", Key: key, Err: err}
+	}
+	_, err = upload.Write(data)
+	if err == nil {
+		err = upload.Commit()
+	}
+	if err != nil {
+		abortErr := upload.Abort()
+		return &TransferError{Op: "upload", Key: key, Err: errs.Combine(err, abortErr)}
+	}
+	fmt.Fprintf(progress(ctx), "Stored manifest of run %s with %d object(s) as %s.\n", manifest.RunID, len(manifest.Objects), key)
+	return nil
+}
+
+// LoadManifest downloads the manifest of the run with the given ID.
+func LoadManifest(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, runID string) (*Manifest, error) {
+
+	defer trace(ctx, "LoadManifest")()
+
+	key := ManifestKey(configStorj, runID)
+	download, err := project.DownloadObject(ctx, configStorj.Bucket, key, nil)
+	if err != nil {
+		if errors.Is(err, uplink.ErrObjectNotFound) {
+			err = fmt.Errorf("no manifest of run %s", runID)
+		}
+		return nil, &TransferError{Op: "download", Key: key, Err: err}
+	}
+	data, err := ioutil.ReadAll(download)
+	if err = errs.Combine(err, download.Close()); err != nil {
+		return nil, &TransferError{Op: "download", Key: key, Err: err}
+	}
+
+	manifest := &Manifest{}
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(manifest); err != nil {
+		return nil, &TransferError{Op: "download", Key: key, Err: fmt.Errorf("invalid manifest: %w", err)}
+	}
+	return manifest, nil
+}
+
+// deleteManifest deletes the manifest of a run.
+func deleteManifest(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, manifest *Manifest) error {
+	key := ManifestKey(configStorj, manifest.RunID)
+	if _, err := project.DeleteObject(ctx, configStorj.Bucket, key); err != nil {
+		return &TransferError{Op: "delete", Key: key, Err: err}
+	}
+	return nil
+}
+
+// ListManifests returns the manifests of all runs, sorted from the newest to the oldest.
+func ListManifests(ctx context.Context, project *uplink.Project, configStorj ConfigStorj) ([]*Manifest, error) {
+
+	defer trace(ctx, "ListManifests")()
+
+	prefix := configStorj.UploadPath + ManifestPrefix
+	iterator := project.ListObjects(ctx, configStorj.Bucket, &uplink.ListObjectsOptions{Prefix: prefix})
+	var manifests []*Manifest
+	for iterator.Next() {
+		item := iterator.Item()
+		if item.IsPrefix || !strings.HasSuffix(item.Key, ".json") {
+			continue
+		}
+		manifest, err := LoadManifest(ctx, project, configStorj, strings.TrimSuffix(strings.TrimPrefix(item.Key, prefix), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, manifest)
+	}
+	if err := iterator.Err(); err != nil {
+		return nil, &TransferError{Op: "list", Key: prefix, Err: err}
+	}
+
+	sort.SliceStable(manifests, func(i, j int) bool {
+		return manifests[i].Started.After(manifests[j].Started)
+	})
+	return manifests, nil
+}
+
+// objectStater looks up objects, implemented by *uplink.Project.
+type objectStater interface {
+	StatObject(ctx context.Context, bucket, key string) (*uplink.Object, error)
+}
+
+// ManifestBackups returns the objects of a run, in the order of its manifest.
+// Objects overwritten by a later run storing the same key are skipped, as
+// they no longer hold the data of this run, see Overwritten.
+func ManifestBackups(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, manifest *Manifest) ([]*uplink.Object, error) {
+	return manifestBackups(ctx, project, configStorj, manifest)
+}
+
+// manifestBackups implements ManifestBackups.
+func manifestBackups(ctx context.Context, project objectStater, configStorj ConfigStorj, manifest *Manifest) ([]*uplink.Object, error) {
+
+	defer trace(ctx, "ManifestBackups")()
+
+	objects := make([]*uplink.Object, 0, len(manifest.Objects))
+	overwritten := 0
+	for _, entry := range manifest.Objects {
+		object, err := project.StatObject(ctx, configStorj.Bucket, entry.Key)
+		if err != nil {
+			return nil, &TransferError{Op: "list", Key: entry.Key, Err: err}
+		}
+		if entry.Overwritten(object.Custom) {
+			overwritten++
+			continue
+		}
+		objects = append(objects, object)
+	}
+	if overwritten > 0 {
+		fmt.Fprintf(progress(ctx), "Skipping %d object(s) of run %s overwritten by a later run.\n", overwritten, manifest.RunID)
+	}
+	return objects, nil
+}
+
+// Overwritten reports whether the object stored under the key of entry, with
+// the given custom metadata, holds other data than the run recorded, as a
+// later run stored the same key. Objects without a recorded checksum are
+// assumed to be unchanged.
+func (entry ManifestObject) Overwritten(metadata uplink.CustomMetadata) bool {
+	stored, err := ChecksumFromMetadata(metadata)
+	if err != nil || stored.IsZero() || entry.Checksum.IsZero() {
+		return false
+	}
+	return stored != entry.Checksum
+}
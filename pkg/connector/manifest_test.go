@@ -0,0 +1,110 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	"storj.io/uplink"
+)
+
+func TestNewManifest(t *testing.T) {
+	config := ConfigStorj{UploadPath: "backups/"}
+	run := Run{ID: "0f8e", Started: time.Date(2021, 3, 31, 15, 4, 5, 0, time.UTC), Host: "db1", Source: "local"}
+	report := &UploadReport{
+		Succeeded: []UploadResult{
+			{Key: "b.sql", Checksum: Checksum{SHA256: "bb", Size: 2}},
+			{Key: "a.sql", Checksum: Checksum{SHA256: "aa", Size: 1}},
+		},
+		Failed: []UploadResult{{Key: "c.sql", Err: errors.New("failed")}},
+	}
+	finished := run.Started.Add(time.Minute)
+
+	manifest := NewManifest(config, run, report, finished)
+	want := &Manifest{
+		RunID:    "0f8e",
+		Version:  Version,
+		Source:   "local",
+		Host:     "db1",
+		Started:  run.Started,
+		Finished: finished,
+		Objects: []ManifestObject{
+			{Key: "backups/a.sql", Checksum: Checksum{SHA256: "aa", Size: 1}},
+			{Key: "backups/b.sql", Checksum: Checksum{SHA256: "bb", Size: 2}},
+		},
+		Failed: 1,
+	}
+	if !reflect.DeepEqual(manifest, want) {
+		t.Errorf("got %+v, want %+v", manifest, want)
+	}
+
+	key := ManifestKey(config, run.ID)
+	if key != "backups/.manifests/0f8e.json" {
+		t.Errorf("got manifest key %q", key)
+	}
+	if !isManifestKey(config, key) || isManifestKey(config, "backups/a.sql") {
+		t.Error("manifest keys not recognized")
+	}
+}
+
+// fakeStater implements objectStater over a map of objects by key.
+type fakeStater map[string]*uplink.Object
+
+func (objects fakeStater) StatObject(ctx context.Context, bucket, key string) (*uplink.Object, error) {
+	object, ok := objects[key]
+	if !ok {
+		return nil, uplink.ErrObjectNotFound
+	}
+	return object, nil
+}
+
+func TestManifestBackupsOverwritten(t *testing.T) {
+	older, newer := Checksum{SHA256: "aa", Size: 1}, Checksum{SHA256: "a2", Size: 2}
+	b := Checksum{SHA256: "bb", Size: 2}
+
+	// Both runs stored a.sql with the default key template, the newer one last.
+	run1 := &Manifest{RunID: "run1", Objects: []ManifestObject{
+		{Key: "backups/a.sql", Checksum: older},
+		{Key: "backups/b.sql", Checksum: b},
+	}}
+	run2 := &Manifest{RunID: "run2", Objects: []ManifestObject{
+		{Key: "backups/a.sql", Checksum: newer},
+	}}
+	project := fakeStater{
+		"backups/a.sql": {Key: "backups/a.sql", Custom: newer.Metadata()},
+		"backups/b.sql": {Key: "backups/b.sql", Custom: b.Metadata()},
+	}
+
+	ctx := WithProgress(context.Background(), ioutil.Discard)
+	config := ConfigStorj{UploadPath: "backups/"}
+	for _, test := range []struct {
+		manifest *Manifest
+		want     []string
+	}{
+		{manifest: run1, want: []string{"backups/b.sql"}},
+		{manifest: run2, want: []string{"backups/a.sql"}},
+	} {
+		objects, err := manifestBackups(ctx, project, config, test.manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for _, object := range objects {
+			keys = append(keys, object.Key)
+		}
+		if !reflect.DeepEqual(keys, test.want) {
+			t.Errorf("%s: got %v, want %v", test.manifest.RunID, keys, test.want)
+		}
+	}
+
+	if !run1.Objects[0].Overwritten(newer.Metadata()) || run2.Objects[0].Overwritten(newer.Metadata()) {
+		t.Error("overwritten object not recognized")
+	}
+	// Without a recorded checksum the object cannot be told apart.
+	if run1.Objects[0].Overwritten(nil) || (ManifestObject{Key: "backups/a.sql"}).Overwritten(newer.Metadata()) {
+		t.Error("object without checksum considered overwritten")
+	}
+}
@@ -0,0 +1,535 @@
+package connector
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minio/sha256-simd"
+	"github.com/zeebo/errs"
+	"storj.io/uplink"
+)
+
+// Defaults of the multipart policy, used for the fields left zero.
+const (
+	DefaultMultipartThreshold = 128 << 20
+	DefaultPartSize           = 64 << 20
+	DefaultRetries            = 3
+	DefaultRetryBackoff       = Duration(time.Second)
+	DefaultUploadStateTTL     = Duration(7 * 24 * time.Hour)
+
+	// MinPartSize is the smallest size the satellite accepts for every part but the last.
+	MinPartSize = 5 << 20
+
+	// maxRetryBackoff caps the exponential backoff between retries.
+	maxRetryBackoff = 30 * time.Second
+)
+
+// MultipartPolicy describes how large items are uploaded in parts.
+// Every part is retried with exponential backoff, and the upload ID of an
+// interrupted upload is kept in StateDir so that a later run uploading the
+// same object key resumes it instead of starting over. Interrupted uploads
+// not resumed within StateTTL are aborted, as pending uploads are billed.
+type MultipartPolicy struct {
+	// Threshold is the size in bytes from which items are uploaded in parts,
+	// DefaultMultipartThreshold if zero. Items of unknown size are uploaded whole.
+	Threshold int64 `json:"threshold"`
+	// PartSize is the size in bytes of the uploaded parts, DefaultPartSize if zero.
+	// It must be at least MinPartSize.
+	PartSize int64 `json:"partSize"`
+	// Retries is the number of retries of a failed part, DefaultRetries if zero.
+	Retries int `json:"retries"`
+	// Backoff is the delay before the first retry, doubled for every further
+	// retry up to 30s, DefaultRetryBackoff if zero.
+	Backoff Duration `json:"backoff"`
+	// StateDir is the directory the state of interrupted uploads is kept in,
+	// connector-framework/uploads in the user cache directory if empty.
+	StateDir string `json:"stateDir"`
+	// StateTTL is the time after which an interrupted upload that was not
+	// resumed is aborted, DefaultUploadStateTTL if zero.
+	StateTTL Duration `json:"stateTTL"`
+}
+
+func (policy MultipartPolicy) threshold() int64 {
+	if policy.Threshold == 0 {
+		return DefaultMultipartThreshold
+	}
+	return policy.Threshold
+}
+
+func (policy MultipartPolicy) partSize() int64 {
+	if policy.PartSize == 0 {
+		return DefaultPartSize
+	}
+	return policy.PartSize
+}
+
+func (policy MultipartPolicy) retries() int {
+	if policy.Retries == 0 {
+		return DefaultRetries
+	}
+	return policy.Retries
+}
+
+func (policy MultipartPolicy) backoff() time.Duration {
+	if policy.Backoff == 0 {
+		return time.Duration(DefaultRetryBackoff)
+	}
+	return time.Duration(policy.Backoff)
+}
+
+func (policy MultipartPolicy) stateTTL() time.Duration {
+	if policy.StateTTL == 0 {
+		return time.Duration(DefaultUploadStateTTL)
+	}
+	return time.Duration(policy.StateTTL)
+}
+
+func (policy MultipartPolicy) stateDir() (string, error) {
+	if policy.StateDir != "" {
+		return policy.StateDir, nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "connector-framework", "uploads"), nil
+}
+
+// objectUpload is the upload of a single object, implemented by
+// *uplink.Upload and by multipartUpload.
+type objectUpload interface {
+	io.Writer
+	SetCustomMetadata(ctx context.Context, custom uplink.CustomMetadata) error
+	Commit() error
+	Abort() error
+}
+
+// multipartProject is the part of *uplink.Project used by multipart uploads.
+type multipartProject interface {
+	BeginUpload(ctx context.Context, bucket, key string, options *uplink.UploadOptions) (uplink.UploadInfo, error)
+	UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber uint32) (partUpload, error)
+	CommitUpload(ctx context.Context, bucket, key, uploadID string, options *uplink.CommitUploadOptions) (*uplink.Object, error)
+	AbortUpload(ctx context.Context, bucket, key, uploadID string) error
+}
+
+// partUpload is the upload of a single part, implemented by *uplink.PartUpload.
+type partUpload interface {
+	io.Writer
+	Commit() error
+	Abort() error
+}
+
+// uploadProject is the part of *uplink.Project used to upload objects.
+type uploadProject interface {
+	UploadObject(ctx context.Context, bucket, key string, options *uplink.UploadOptions) (objectUpload, error)
+	multipartProject
+}
+
+// uplinkProject adapts *uplink.Project to uploadProject.
+type uplinkProject struct {
+	*uplink.Project
+}
+
+// UploadObject starts the upload of a whole object.
+func (project uplinkProject) UploadObject(ctx context.Context, bucket, key string, options *uplink.UploadOptions) (objectUpload, error) {
+	upload, err := project.Project.UploadObject(ctx, bucket, key, options)
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// UploadPart starts the upload of a part of the multipart upload.
+func (project uplinkProject) UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber uint32) (partUpload, error) {
+	upload, err := project.Project.UploadPart(ctx, bucket, key, uploadID, partNumber)
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// sourceInfo identifies the version of the uploaded item, so that an
+// interrupted upload is only resumed with the same data.
+type sourceInfo struct {
+	// Size is the size of the item in bytes, or -1 if unknown.
+	Size int64 `json:"size"`
+	// ModTime is the last modification time of the item, zero if unknown.
+	ModTime time.Time `json:"modTime"`
+}
+
+// beginUpload starts the upload of the object stored under key, in parts
+// if the expected size of the item reaches the multipart threshold.
+func beginUpload(ctx context.Context, project uploadProject, configStorj ConfigStorj, key string, item sourceInfo) (objectUpload, error) {
+	if item.Size < 0 || item.Size < configStorj.Multipart.threshold() {
+		return project.UploadObject(ctx, configStorj.Bucket, key, nil)
+	}
+	return newMultipartUpload(ctx, project, configStorj.Bucket, key, item, configStorj.Multipart)
+}
+
+// retry calls fn until it succeeds, fails with an error that is not
+// retryable or has been retried policy.Retries times. The delay between
+// the attempts doubles from policy.Backoff.
+func retry(ctx context.Context, policy MultipartPolicy, op string, fn func() error) error {
+	delay := policy.backoff()
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= policy.retries() || !retryable(err) {
+			return err
+		}
+		fmt.Fprintf(progress(ctx), "Failed to %s, retrying in %s: %v\n", op, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%v: %w", err, ctx.Err())
+		case <-timer.C:
+		}
+		if delay *= 2; delay > maxRetryBackoff {
+			delay = maxRetryBackoff
+		}
+	}
+}
+
+// retryable reports whether err may be caused by a transient failure,
+// such as a network error, rather than by the request itself.
+func retryable(err error) bool {
+	for _, permanent := range []error{
+		context.Canceled,
+		context.DeadlineExceeded,
+		uplink.ErrPermissionDenied,
+		uplink.ErrBandwidthLimitExceeded,
+		uplink.ErrBucketNotFound,
+		uplink.ErrBucketNameInvalid,
+		uplink.ErrObjectKeyInvalid,
+		uplink.ErrUploadIDInvalid,
+		errResumeMismatch,
+	} {
+		if errors.Is(err, permanent) {
+			return false
+		}
+	}
+	return !rejected(err)
+}
+
+// rpcInvalidArgument is the status code of the requests the satellite rejects
+// as invalid, such as a commit of parts smaller than MinPartSize.
+// See storj.io/common/rpc/rpcstatus.
+const rpcInvalidArgument = 3
+
+// rejected reports whether the satellite rejected the request as invalid.
+func rejected(err error) bool {
+	var coded interface{ Code() uint64 }
+	return errors.As(err, &coded) && coded.Code() == rpcInvalidArgument
+}
+
+// errResumeMismatch is returned when the data of an interrupted upload
+// differs from the data uploaded again, e.g. as the item was modified.
+var errResumeMismatch = errors.New("data differs from the interrupted upload, it will restart on the next run")
+
+// uploadState is the state of a multipart upload persisted between runs.
+type uploadState struct {
+	Bucket   string         `json:"bucket"`
+	Key      string         `json:"key"`
+	UploadID string         `json:"uploadId"`
+	PartSize int64          `json:"partSize"`
+	Item     sourceInfo     `json:"item"`
+	Parts    []uploadedPart `json:"parts"`
+}
+
+// resumes reports whether the upload of item to key in parts of partSize
+// can resume the upload recorded by state.
+func (state *uploadState) resumes(bucket, key string, item sourceInfo, partSize int64) bool {
+	return state.Bucket == bucket && state.Key == key && state.PartSize == partSize &&
+		state.Item.Size == item.Size && state.Item.ModTime.Equal(item.ModTime)
+}
+
+// uploadedPart is a committed part of a multipart upload.
+type uploadedPart struct {
+	Number uint32 `json:"number"`
+	Size   int64  `json:"size"`
+	// SHA256 is the checksum of the stored data of the part, after the transforms.
+	SHA256 string `json:"sha256"`
+}
+
+// uploadStatePath returns the path of the state file of the upload of key to bucket.
+func uploadStatePath(stateDir, bucket, key string) string {
+	sum := sha256.Sum256([]byte(bucket + "/" + key))
+	return filepath.Join(stateDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// loadUploadState reads the state file at path, returning nil if it does not exist.
+func loadUploadState(path string) (*uploadState, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := &uploadState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid upload state %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// save replaces the state file at path.
+func (state *uploadState) save(path string) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	// Write a temporary file first, so that a crash never leaves a truncated state.
+	temp := path + ".tmp"
+	if err := ioutil.WriteFile(temp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(temp, path)
+}
+
+// multipartUpload uploads an object in parts of a fixed size, resuming the
+// interrupted upload of the same object recorded in its state file.
+type multipartUpload struct {
+	ctx       context.Context
+	project   multipartProject
+	policy    MultipartPolicy
+	statePath string
+	// state lists the parts committed on the network, including the ones
+	// of an interrupted upload, skipped if the same data is uploaded again.
+	state *uploadState
+	// discard is set when the interrupted upload cannot be resumed.
+	discard bool
+	item    sourceInfo
+
+	// buf holds the data of the next part.
+	buf      []byte
+	next     uint32
+	metadata uplink.CustomMetadata
+}
+
+// newMultipartUpload begins the multipart upload of the object stored under
+// key, or resumes the interrupted one recorded in the state directory.
+func newMultipartUpload(ctx context.Context, project multipartProject, bucket, key string, item sourceInfo, policy MultipartPolicy) (*multipartUpload, error) {
+	stateDir, err := policy.stateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &multipartUpload{
+		ctx:       ctx,
+		project:   project,
+		policy:    policy,
+		statePath: uploadStatePath(stateDir, bucket, key),
+		item:      item,
+		buf:       make([]byte, 0, policy.partSize()),
+		next:      1,
+	}
+
+	state, err := loadUploadState(upload.statePath)
+	if err != nil {
+		return nil, err
+	}
+	if state != nil && state.resumes(bucket, key, item, policy.partSize()) {
+		fmt.Fprintf(progress(ctx), "Resuming interrupted upload of %s with %d part(s) uploaded.\n", key, len(state.Parts))
+		upload.state = state
+		return upload, nil
+	}
+	if state != nil {
+		// The item or the part size changed, so the parts cannot be reused.
+		fmt.Fprintf(progress(ctx), "Restarting upload of %s, the item changed since it was interrupted.\n", key)
+		_ = project.AbortUpload(ctx, state.Bucket, state.Key, state.UploadID)
+	}
+
+	if err := upload.begin(bucket, key); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// begin starts a new multipart upload and records it in the state file.
+func (upload *multipartUpload) begin(bucket, key string) error {
+	var info uplink.UploadInfo
+	err := retry(upload.ctx, upload.policy, "begin upload", func() (err error) {
+		info, err = upload.project.BeginUpload(upload.ctx, bucket, key, nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	upload.state = &uploadState{Bucket: bucket, Key: key, UploadID: info.UploadID, PartSize: upload.policy.partSize(), Item: upload.item}
+	return upload.state.save(upload.statePath)
+}
+
+// Write buffers p, uploading every complete part.
+func (upload *multipartUpload) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := cap(upload.buf) - len(upload.buf)
+		if n > len(p) {
+			n = len(p)
+		}
+		upload.buf = append(upload.buf, p[:n]...)
+		p = p[n:]
+		if len(upload.buf) == cap(upload.buf) {
+			if err := upload.flush(); err != nil {
+				return written, err
+			}
+		}
+		written += n
+	}
+	return written, nil
+}
+
+// flush uploads the buffered data as the next part, unless the interrupted
+// upload already committed the same data.
+func (upload *multipartUpload) flush() error {
+	number := upload.next
+	sum := sha256.Sum256(upload.buf)
+	part := uploadedPart{Number: number, Size: int64(len(upload.buf)), SHA256: hex.EncodeToString(sum[:])}
+
+	if index := int(number) - 1; index < len(upload.state.Parts) {
+		switch {
+		case upload.state.Parts[index] == part:
+			fmt.Fprintf(progress(upload.ctx), "Skipping part %d of %s, already uploaded.\n", number, upload.state.Key)
+			upload.buf = upload.buf[:0]
+			upload.next++
+			return nil
+		case index == 0:
+			// Nothing was reused yet, so start over.
+			fmt.Fprintf(progress(upload.ctx), "Restarting upload of %s, the data changed since it was interrupted.\n", upload.state.Key)
+			_ = upload.project.AbortUpload(upload.ctx, upload.state.Bucket, upload.state.Key, upload.state.UploadID)
+			if err := upload.begin(upload.state.Bucket, upload.state.Key); err != nil {
+				return err
+			}
+		default:
+			upload.discard = true
+			return errResumeMismatch
+		}
+	}
+
+	err := retry(upload.ctx, upload.policy, fmt.Sprintf("upload part %d", number), func() error {
+		partUpload, err := upload.project.UploadPart(upload.ctx, upload.state.Bucket, upload.state.Key, upload.state.UploadID, number)
+		if err != nil {
+			return err
+		}
+		if _, err = partUpload.Write(upload.buf); err == nil {
+			err = partUpload.Commit()
+		}
+		if err != nil {
+			_ = partUpload.Abort()
+		}
+		return err
+	})
+	if err != nil {
+		// Retrying later cannot help, e.g. as the pending upload expired,
+		// unless the run was interrupted.
+		upload.discard = !retryable(err) && upload.ctx.Err() == nil
+		return err
+	}
+	return upload.commitPart(part)
+}
+
+// commitPart records the uploaded part and resets the buffer for the next one.
+func (upload *multipartUpload) commitPart(part uploadedPart) error {
+	upload.state.Parts = append(upload.state.Parts, part)
+	upload.buf = upload.buf[:0]
+	upload.next++
+	return upload.state.save(upload.statePath)
+}
+
+// SetCustomMetadata sets the custom metadata the object is committed with.
+func (upload *multipartUpload) SetCustomMetadata(ctx context.Context, custom uplink.CustomMetadata) error {
+	upload.metadata = custom.Clone()
+	return nil
+}
+
+// Commit uploads the last part and commits the object.
+func (upload *multipartUpload) Commit() error {
+	if len(upload.buf) > 0 {
+		if err := upload.flush(); err != nil {
+			return err
+		}
+	}
+	if int(upload.next)-1 < len(upload.state.Parts) {
+		// The data is shorter than the interrupted upload.
+		upload.discard = true
+		return errResumeMismatch
+	}
+
+	err := retry(upload.ctx, upload.policy, "commit upload", func() error {
+		_, err := upload.project.CommitUpload(upload.ctx, upload.state.Bucket, upload.state.Key, upload.state.UploadID,
+			&uplink.CommitUploadOptions{CustomMetadata: upload.metadata})
+		return err
+	})
+	if err != nil {
+		upload.discard = !retryable(err) && upload.ctx.Err() == nil
+		return err
+	}
+	return os.Remove(upload.statePath)
+}
+
+// Abort keeps an upload with committed parts pending, so that the next run
+// resumes it, and aborts it otherwise.
+func (upload *multipartUpload) Abort() error {
+	if !upload.discard && len(upload.state.Parts) > 0 {
+		fmt.Fprintf(progress(upload.ctx), "Upload of %s interrupted after %d part(s), it will resume on the next run.\n", upload.state.Key, len(upload.state.Parts))
+		return nil
+	}
+	err := upload.project.AbortUpload(upload.ctx, upload.state.Bucket, upload.state.Key, upload.state.UploadID)
+	if removeErr := os.Remove(upload.statePath); removeErr != nil && !os.IsNotExist(removeErr) && err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// cleanupUploads aborts the interrupted uploads whose state was not updated
+// within the TTL of the policy and removes their state files.
+func cleanupUploads(ctx context.Context, project multipartProject, policy MultipartPolicy, now time.Time) error {
+	stateDir, err := policy.stateDir()
+	if err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(stateDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var group errs.Group
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" || now.Sub(file.ModTime()) < policy.stateTTL() {
+			continue
+		}
+		path := filepath.Join(stateDir, file.Name())
+		state, err := loadUploadState(path)
+		if err != nil || state == nil {
+			group.Add(err)
+			continue
+		}
+		fmt.Fprintf(progress(ctx), "Aborting upload of %s interrupted on %s.\n", state.Key, file.ModTime().Format(time.RFC3339))
+		err = project.AbortUpload(ctx, state.Bucket, state.Key, state.UploadID)
+		if err != nil && !errors.Is(err, uplink.ErrUploadIDInvalid) {
+			// Keep the state, so that the next run tries again.
+			group.Add(fmt.Errorf("abort upload of %s: %w", state.Key, err))
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			group.Add(err)
+		}
+	}
+	return group.Err()
+}
@@ -0,0 +1,414 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"storj.io/uplink"
+)
+
+// codeError is an error with an RPC status code, like the errors of rpcstatus.
+type codeError uint64
+
+func (err codeError) Error() string { return fmt.Sprintf("rpc status %d", uint64(err)) }
+func (err codeError) Code() uint64  { return uint64(err) }
+
+func TestRetry(t *testing.T) {
+	policy := MultipartPolicy{Retries: 2, Backoff: Duration(time.Millisecond)}
+	transient := errors.New("connection reset by peer")
+	rejected := fmt.Errorf("metaclient: %w", codeError(rpcInvalidArgument))
+
+	tests := []struct {
+		name     string
+		errs     []error
+		attempts int
+		err      error
+	}{
+		{name: "success", errs: []error{nil}, attempts: 1},
+		{name: "transient", errs: []error{transient, transient, nil}, attempts: 3},
+		{name: "exhausted", errs: []error{transient, transient, transient, nil}, attempts: 3, err: transient},
+		{name: "permanent", errs: []error{uplink.ErrPermissionDenied, nil}, attempts: 1, err: uplink.ErrPermissionDenied},
+		{name: "rejected", errs: []error{rejected, nil}, attempts: 1, err: rejected},
+		{name: "unavailable", errs: []error{codeError(14), nil}, attempts: 2},
+	}
+	for _, test := range tests {
+		attempts := 0
+		err := retry(context.Background(), policy, "upload part", func() error {
+			attempts++
+			return test.errs[attempts-1]
+		})
+		if attempts != test.attempts {
+			t.Errorf("%s: %d attempts, want %d", test.name, attempts, test.attempts)
+		}
+		if !errors.Is(err, test.err) || (err == nil) != (test.err == nil) {
+			t.Errorf("%s: got error %v, want %v", test.name, err, test.err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := retry(ctx, MultipartPolicy{Backoff: Duration(time.Hour)}, "upload part", func() error { return transient })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled: got error %v", err)
+	}
+}
+
+func TestUploadState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := uploadStatePath(filepath.Join(dir, "state"), "backups", "db01/dump.sql")
+	if path == uploadStatePath(filepath.Join(dir, "state"), "backups", "db02/dump.sql") {
+		t.Fatal("state paths of different keys are equal")
+	}
+
+	state, err := loadUploadState(path)
+	if state != nil || err != nil {
+		t.Fatalf("got state %+v, error %v before saving", state, err)
+	}
+
+	want := &uploadState{
+		Bucket:   "backups",
+		Key:      "db01/dump.sql",
+		UploadID: "upload",
+		PartSize: DefaultPartSize,
+		Item:     sourceInfo{Size: 2 * DefaultPartSize, ModTime: time.Date(2021, 3, 31, 12, 0, 0, 0, time.UTC)},
+		Parts:    []uploadedPart{{Number: 1, Size: DefaultPartSize, SHA256: "00"}},
+	}
+	if err := want.save(path); err != nil {
+		t.Fatal(err)
+	}
+	state, err = loadUploadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("got state %+v, want %+v", state, want)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("got state file %v, error %v", info, err)
+	}
+}
+
+func TestMultipartPolicyDefaults(t *testing.T) {
+	var policy MultipartPolicy
+	if policy.threshold() != DefaultMultipartThreshold || policy.partSize() != DefaultPartSize ||
+		policy.retries() != DefaultRetries || policy.backoff() != time.Duration(DefaultRetryBackoff) ||
+		policy.stateTTL() != time.Duration(DefaultUploadStateTTL) {
+		t.Errorf("unexpected defaults")
+	}
+	if dir, err := policy.stateDir(); err == nil && filepath.Base(dir) != "uploads" {
+		t.Errorf("got state directory %s", dir)
+	}
+}
+
+// fakeProject implements multipartProject in memory.
+type fakeProject struct {
+	uploads   map[string]*fakeUpload
+	begun     int
+	parts     int
+	aborted   []string
+	committed map[string]string
+
+	// commitErr is returned when committing a multipart upload.
+	commitErr error
+
+	// interruptAt cancels the context of the upload before uploading
+	// the part with this number.
+	interruptAt uint32
+	cancel      func()
+
+	// mu guards committed and objectErrs for concurrent whole object uploads.
+	mu sync.Mutex
+	// objectErrs are returned when starting the upload of a whole object by key.
+	objectErrs map[string]error
+}
+
+type fakeUpload struct {
+	key   string
+	parts map[uint32][]byte
+}
+
+func newFakeProject() *fakeProject {
+	return &fakeProject{uploads: make(map[string]*fakeUpload), committed: make(map[string]string)}
+}
+
+func (project *fakeProject) BeginUpload(ctx context.Context, bucket, key string, options *uplink.UploadOptions) (uplink.UploadInfo, error) {
+	project.begun++
+	id := fmt.Sprintf("upload%d", project.begun)
+	project.uploads[id] = &fakeUpload{key: key, parts: make(map[uint32][]byte)}
+	return uplink.UploadInfo{UploadID: id, Key: key}, nil
+}
+
+func (project *fakeProject) UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber uint32) (partUpload, error) {
+	if partNumber == project.interruptAt && project.cancel != nil {
+		project.cancel()
+		return nil, ctx.Err()
+	}
+	upload, ok := project.uploads[uploadID]
+	if !ok {
+		return nil, uplink.ErrUploadIDInvalid
+	}
+	project.parts++
+	return &fakePart{upload: upload, number: partNumber}, nil
+}
+
+func (project *fakeProject) CommitUpload(ctx context.Context, bucket, key, uploadID string, options *uplink.CommitUploadOptions) (*uplink.Object, error) {
+	upload, ok := project.uploads[uploadID]
+	if !ok {
+		return nil, uplink.ErrUploadIDInvalid
+	}
+	if project.commitErr != nil {
+		return nil, project.commitErr
+	}
+	var numbers []int
+	for number := range upload.parts {
+		numbers = append(numbers, int(number))
+	}
+	sort.Ints(numbers)
+	var data bytes.Buffer
+	for _, number := range numbers {
+		data.Write(upload.parts[uint32(number)])
+	}
+	project.committed[key] = data.String()
+	delete(project.uploads, uploadID)
+	return &uplink.Object{Key: key}, nil
+}
+
+func (project *fakeProject) AbortUpload(ctx context.Context, bucket, key, uploadID string) error {
+	if _, ok := project.uploads[uploadID]; !ok {
+		return uplink.ErrUploadIDInvalid
+	}
+	delete(project.uploads, uploadID)
+	project.aborted = append(project.aborted, uploadID)
+	return nil
+}
+
+func (project *fakeProject) UploadObject(ctx context.Context, bucket, key string, options *uplink.UploadOptions) (objectUpload, error) {
+	project.mu.Lock()
+	defer project.mu.Unlock()
+	if err := project.objectErrs[key]; err != nil {
+		return nil, err
+	}
+	return &fakeObjectUpload{project: project, key: key}, nil
+}
+
+// fakeObjectUpload is the upload of a whole object to a fakeProject.
+type fakeObjectUpload struct {
+	project  *fakeProject
+	key      string
+	data     bytes.Buffer
+	metadata uplink.CustomMetadata
+}
+
+func (upload *fakeObjectUpload) Write(p []byte) (int, error) {
+	return upload.data.Write(p)
+}
+
+func (upload *fakeObjectUpload) SetCustomMetadata(ctx context.Context, custom uplink.CustomMetadata) error {
+	upload.metadata = custom.Clone()
+	return nil
+}
+
+func (upload *fakeObjectUpload) Commit() error {
+	upload.project.mu.Lock()
+	defer upload.project.mu.Unlock()
+	upload.project.committed[upload.key] = upload.data.String()
+	return nil
+}
+
+func (upload *fakeObjectUpload) Abort() error { return nil }
+
+type fakePart struct {
+	upload *fakeUpload
+	number uint32
+	data   []byte
+}
+
+func (part *fakePart) Write(p []byte) (int, error) {
+	part.data = append(part.data, p...)
+	return len(p), nil
+}
+
+func (part *fakePart) Commit() error {
+	part.upload.parts[part.number] = part.data
+	return nil
+}
+
+func (part *fakePart) Abort() error { return nil }
+
+func TestMultipartUpload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	policy := MultipartPolicy{PartSize: 4, Retries: 1, Backoff: Duration(time.Millisecond), StateDir: dir}
+	item := sourceInfo{Size: 10, ModTime: time.Date(2021, 3, 31, 12, 0, 0, 0, time.UTC)}
+	statePath := uploadStatePath(dir, "backups", "dump.sql")
+
+	// upload uploads data like uploadData, aborting on failure.
+	upload := func(ctx context.Context, project *fakeProject, item sourceInfo, data string) error {
+		upload, err := newMultipartUpload(ctx, project, "backups", "dump.sql", item, policy)
+		if err != nil {
+			return err
+		}
+		_, err = upload.Write([]byte(data))
+		if err == nil {
+			err = upload.Commit()
+		}
+		if err != nil {
+			if abortErr := upload.Abort(); abortErr != nil {
+				t.Errorf("abort: %v", abortErr)
+			}
+		}
+		return err
+	}
+	// interrupt cancels the upload of data before uploading part number.
+	interrupt := func(project *fakeProject, number uint32, data string) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		project.interruptAt, project.cancel = number, cancel
+		err := upload(ctx, project, item, data)
+		project.interruptAt, project.cancel = 0, nil
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("interrupted upload: got error %v", err)
+		}
+		// The upload is kept pending to be resumed.
+		if _, err := os.Stat(statePath); err != nil || len(project.aborted) != 0 || len(project.uploads) != 1 {
+			t.Fatalf("interrupted upload not kept: state %v, aborted %v", err, project.aborted)
+		}
+	}
+	stateRemoved := func(name string) {
+		if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+			t.Errorf("%s: state file not removed: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		// The data is interrupted before uploading part interruptAt,
+		// then uploaded again as resumed.
+		data        string
+		interruptAt uint32
+		resumed     string
+		item        sourceInfo
+		err         error
+		parts       int
+		restarted   bool
+	}{
+		{name: "unchanged", data: "aaaabbbbcc", interruptAt: 2, resumed: "aaaabbbbcc", item: item, parts: 2},
+		{name: "first part changed", data: "aaaabbbbcc", interruptAt: 2, resumed: "xxxxbbbbcc", item: item, parts: 3, restarted: true},
+		{name: "item changed", data: "aaaabbbbcc", interruptAt: 3, resumed: "aaaaxxxxcc", item: sourceInfo{Size: 10, ModTime: item.ModTime.Add(time.Hour)}, parts: 3, restarted: true},
+		{name: "later part changed", data: "aaaabbbbcc", interruptAt: 3, resumed: "aaaaxxxxcc", item: item, err: errResumeMismatch},
+		{name: "shorter", data: "aaaabbbbcc", interruptAt: 3, resumed: "aaaa", item: item, err: errResumeMismatch},
+	}
+	for _, test := range tests {
+		project := newFakeProject()
+		interrupt(project, test.interruptAt, test.data)
+		project.parts = 0
+
+		err := upload(context.Background(), project, test.item, test.resumed)
+		if !errors.Is(err, test.err) || (err == nil) != (test.err == nil) {
+			t.Errorf("%s: got error %v, want %v", test.name, err, test.err)
+		}
+		stateRemoved(test.name)
+		if test.err != nil {
+			// The upload that cannot be resumed is discarded.
+			if len(project.uploads) != 0 || len(project.aborted) != 1 || len(project.committed) != 0 {
+				t.Errorf("%s: pending %v, aborted %v, committed %v", test.name, project.uploads, project.aborted, project.committed)
+			}
+			continue
+		}
+		if got := project.committed["dump.sql"]; got != test.resumed {
+			t.Errorf("%s: committed %q, want %q", test.name, got, test.resumed)
+		}
+		if project.parts != test.parts {
+			t.Errorf("%s: uploaded %d parts, want %d", test.name, project.parts, test.parts)
+		}
+		if restarted := project.begun == 2; restarted != test.restarted || len(project.aborted) != project.begun-1 {
+			t.Errorf("%s: begun %d uploads, aborted %v", test.name, project.begun, project.aborted)
+		}
+	}
+
+	// A commit the satellite rejects is not retried and the upload is discarded.
+	project := newFakeProject()
+	project.commitErr = codeError(rpcInvalidArgument)
+	err = upload(context.Background(), project, item, "aaaabbbbcc")
+	if !errors.Is(err, project.commitErr) {
+		t.Errorf("rejected commit: got error %v", err)
+	}
+	stateRemoved("rejected commit")
+	if len(project.uploads) != 0 || len(project.aborted) != 1 || len(project.committed) != 0 {
+		t.Errorf("rejected commit: pending %v, aborted %v, committed %v", project.uploads, project.aborted, project.committed)
+	}
+}
+
+func TestCleanupUploads(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	now := time.Now()
+	policy := MultipartPolicy{StateDir: dir, StateTTL: Duration(24 * time.Hour)}
+	project := newFakeProject()
+
+	states := []struct {
+		key  string
+		age  time.Duration
+		kept bool
+	}{
+		{key: "recent.sql", age: time.Hour, kept: true},
+		{key: "stale.sql", age: 48 * time.Hour},
+		// The pending upload of this one expired already.
+		{key: "expired.sql", age: 48 * time.Hour},
+	}
+	for _, state := range states {
+		info, _ := project.BeginUpload(context.Background(), "backups", state.key, nil)
+		if state.key == "expired.sql" {
+			delete(project.uploads, info.UploadID)
+		}
+		path := uploadStatePath(dir, "backups", state.key)
+		if err := (&uploadState{Bucket: "backups", Key: state.key, UploadID: info.UploadID}).save(path); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-state.age), now.Add(-state.age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := cleanupUploads(context.Background(), project, policy, now); err != nil {
+		t.Fatal(err)
+	}
+	for _, state := range states {
+		_, err := os.Stat(uploadStatePath(dir, "backups", state.key))
+		if kept := err == nil; kept != state.kept {
+			t.Errorf("%s: state kept %v, want %v", state.key, kept, state.kept)
+		}
+	}
+	if !reflect.DeepEqual(project.aborted, []string{"upload2"}) || len(project.uploads) != 1 {
+		t.Errorf("aborted %v, pending %v", project.aborted, project.uploads)
+	}
+
+	// A missing state directory is not an error.
+	policy.StateDir = filepath.Join(dir, "missing")
+	if err := cleanupUploads(context.Background(), project, policy, now); err != nil {
+		t.Errorf("missing state directory: %v", err)
+	}
+}
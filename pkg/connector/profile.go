@@ -0,0 +1,91 @@
+package connector
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Keys of a Storj configuration holding named profiles.
+const (
+	ProfilesKey       = "profiles"
+	DefaultProfileKey = "defaultProfile"
+)
+
+// DefaultProfile is the profile used when none is selected
+// and the configuration does not name a default profile.
+const DefaultProfile = "default"
+
+// ProfileEnv is the environment variable selecting the profile
+// when none is given explicitly.
+const ProfileEnv = StorjEnvPrefix + "PROFILE"
+
+// selectProfile returns the Storj configuration of the named profile if data
+// holds profiles, merged over the fields outside of the profiles, which are
+// shared by every profile. If name is empty the profile named by ProfileEnv,
+// the defaultProfile field or DefaultProfile is used, in that order.
+// Without profiles data is returned as is, unless a name is given.
+func selectProfile(data []byte, name string) ([]byte, error) {
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	profiles, ok := document[ProfilesKey].(map[string]interface{})
+	if !ok {
+		if name != "" {
+			return nil, fmt.Errorf("cannot select profile %q: no %q defined", name, ProfilesKey)
+		}
+		return data, nil
+	}
+
+	if name == "" {
+		name = os.Getenv(ProfileEnv)
+	}
+	if name == "" {
+		name, _ = document[DefaultProfileKey].(string)
+	}
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	profile, ok := profiles[name].(map[string]interface{})
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for profileName := range profiles {
+			names = append(names, profileName)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown profile %q (defined: %s)", name, strings.Join(names, ", "))
+	}
+
+	delete(document, ProfilesKey)
+	delete(document, DefaultProfileKey)
+	mergeObjects(document, profile)
+	return json.Marshal(document)
+}
+
+// mergeObjects sets the values of src in dst, merging nested objects.
+// JSON keys match struct fields case-insensitively, so keys of dst
+// differing only in case are replaced.
+func mergeObjects(dst, src map[string]interface{}) {
+	for key, value := range src {
+		var existing interface{}
+		for k, v := range dst {
+			if strings.EqualFold(k, key) {
+				existing = v
+				delete(dst, k)
+			}
+		}
+
+		srcObject, srcOK := value.(map[string]interface{})
+		dstObject, dstOK := existing.(map[string]interface{})
+		if srcOK && dstOK {
+			mergeObjects(dstObject, srcObject)
+			value = dstObject
+		}
+		dst[key] = value
+	}
+}
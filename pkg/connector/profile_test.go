@@ -0,0 +1,55 @@
+package connector
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestSelectProfile(t *testing.T) {
+	data := []byte(`{
+		"encryptionpassphrase": "shared",
+		"retention": {"keepDaily": 7},
+		"defaultProfile": "us",
+		"profiles": {
+			"us": {"bucket": "us-backups"},
+			"eu": {"bucket": "eu-backups", "Retention": {"keepLast": 3}}
+		}
+	}`)
+
+	tests := []struct {
+		profile string
+		env     string
+		want    ConfigStorj
+	}{
+		{"", "", ConfigStorj{EncryptionPassphrase: "shared", Bucket: "us-backups", Retention: RetentionPolicy{KeepDaily: 7}}},
+		{"", "eu", ConfigStorj{EncryptionPassphrase: "shared", Bucket: "eu-backups", Retention: RetentionPolicy{KeepLast: 3, KeepDaily: 7}}},
+		{"us", "eu", ConfigStorj{EncryptionPassphrase: "shared", Bucket: "us-backups", Retention: RetentionPolicy{KeepDaily: 7}}},
+	}
+
+	defer func() { _ = os.Unsetenv(ProfileEnv) }()
+	for _, test := range tests {
+		if err := os.Setenv(ProfileEnv, test.env); err != nil {
+			t.Fatal(err)
+		}
+		selected, err := selectProfile(data, test.profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var config ConfigStorj
+		if err := json.Unmarshal(selected, &config); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(config, test.want) {
+			t.Errorf("profile %q, %s=%q: got %+v, want %+v", test.profile, ProfileEnv, test.env, config, test.want)
+		}
+	}
+
+	if _, err := selectProfile(data, "asia"); err == nil {
+		t.Error("expected an error selecting an unknown profile")
+	}
+	if _, err := selectProfile([]byte(`{"bucket": "backups"}`), "us"); err == nil {
+		t.Error("expected an error selecting a profile of a configuration without profiles")
+	}
+}
@@ -0,0 +1,24 @@
+package connector
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+type progressKey struct{}
+
+// WithProgress returns a copy of ctx that makes the functions of this package
+// write their progress messages to w instead of stdout, e.g. ioutil.Discard
+// to silence them. The CLI uses it to keep stdout for the result of a command.
+func WithProgress(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, progressKey{}, w)
+}
+
+// progress returns the writer for progress messages carried by ctx, stdout by default.
+func progress(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(progressKey{}).(io.Writer); ok && w != nil {
+		return w
+	}
+	return os.Stdout
+}
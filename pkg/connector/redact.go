@@ -0,0 +1,79 @@
+package connector
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Redacted replaces the value of sensitive configuration fields when printed.
+const Redacted = "[REDACTED]"
+
+// ConfigField is a single printable configuration value.
+type ConfigField struct {
+	// Name is the JSON path of the field, e.g. retention.keepLast.
+	Name      string
+	Value     string
+	Sensitive bool
+}
+
+// RedactConfig flattens the struct config into printable fields.
+// Fields tagged `sensitive:"true"` are replaced by Redacted
+// unless showSecrets is set; empty sensitive fields are left empty.
+func RedactConfig(config interface{}, showSecrets bool) []ConfigField {
+	var fields []ConfigField
+	collectFields(reflect.ValueOf(config), "", false, showSecrets, &fields)
+	return fields
+}
+
+// PrintConfig prints the configuration read from fileName through RedactConfig.
+func PrintConfig(w io.Writer, title string, fileName string, config interface{}, showSecrets bool) {
+	fmt.Fprintln(w, "Read", title, "configuration from the", fileName, "file.")
+
+	writer := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	for _, field := range RedactConfig(config, showSecrets) {
+		fmt.Fprintf(writer, "%s\t: %s\n", field.Name, field.Value)
+	}
+	_ = writer.Flush()
+}
+
+// collectFields appends the printable fields of v to fields.
+func collectFields(v reflect.Value, prefix string, sensitive, showSecrets bool, fields *[]ConfigField) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	_, isStringer := v.Interface().(fmt.Stringer)
+	if v.Kind() == reflect.Struct && !isStringer {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue // unexported
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			collectFields(v.Field(i), name, sensitive || field.Tag.Get("sensitive") == "true", showSecrets, fields)
+		}
+		return
+	}
+
+	value := fmt.Sprint(v.Interface())
+	if sensitive && !showSecrets && !v.IsZero() {
+		value = Redacted
+	}
+	*fields = append(*fields, ConfigField{Name: prefix, Value: value, Sensitive: sensitive})
+}
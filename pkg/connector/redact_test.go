@@ -0,0 +1,36 @@
+package connector
+
+import (
+	"testing"
+)
+
+func TestRedactConfig(t *testing.T) {
+	config := ConfigStorj{
+		APIKey:    "13Yqe...",
+		Satellite: "us-central-1.tardigrade.io:7777",
+		Retention: RetentionPolicy{KeepLast: 3},
+	}
+
+	values := make(map[string]string)
+	for _, field := range RedactConfig(config, false) {
+		values[field.Name] = field.Value
+	}
+
+	tests := map[string]string{
+		"apikey":             Redacted,
+		"satellite":          "us-central-1.tardigrade.io:7777",
+		"serializedAccess":   "",
+		"retention.keepLast": "3",
+	}
+	for name, want := range tests {
+		if got := values[name]; got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+
+	for _, field := range RedactConfig(config, true) {
+		if field.Name == "apikey" && field.Value != config.APIKey {
+			t.Errorf("apikey: got %q with secrets shown, want %q", field.Value, config.APIKey)
+		}
+	}
+}
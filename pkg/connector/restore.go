@@ -0,0 +1,161 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"storj.io/uplink"
+)
+
+// RestoreOptions selects the backups restored by RestoreBackups.
+type RestoreOptions struct {
+	// Key is the object key or prefix, relative to the upload path, to restore.
+	Key string
+	// Destination is the local directory the backups are written to.
+	Destination string
+	// Latest restores only the objects of the most recent backup, the run
+	// recorded by a manifest or the entry below the upload path if the key
+	// template groups runs, see KeyTemplate.GroupsByRun.
+	Latest bool
+	// At, if not zero, restores only the objects of the latest backup
+	// created at or before it.
+	At time.Time
+	// RunID, if set, restores only the objects in the manifest of that run
+	// whose keys start with Key. It cannot be combined with Latest or At.
+	RunID string
+}
+
+// RestoreBackups downloads the selected backups below the destination directory,
+// keeping their path relative to the upload path.
+func RestoreBackups(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, options RestoreOptions) error {
+
+	defer trace(ctx, "RestoreBackups")()
+
+	if options.RunID != "" {
+		if options.Latest || !options.At.IsZero() {
+			return &ConfigError{Err: errors.New("the latest and at options cannot be combined with a run ID")}
+		}
+		objects, err := runBackups(ctx, project, configStorj, options.RunID, options.Key)
+		if err != nil {
+			return err
+		}
+		return restoreObjects(ctx, project, configStorj, options, objects)
+	}
+
+	objects, err := ListBackups(ctx, project, configStorj, options.Key, true)
+	if err != nil {
+		return err
+	}
+	var manifests []*Manifest
+	if options.Latest || !options.At.IsZero() {
+		if manifests, err = ListManifests(ctx, project, configStorj); err != nil {
+			return err
+		}
+	}
+	objects, err = selectBackups(objects, manifests, configStorj, options.Latest, options.At)
+	if err != nil {
+		return err
+	}
+	return restoreObjects(ctx, project, configStorj, options, objects)
+}
+
+// restoreObjects downloads the objects below the destination directory.
+func restoreObjects(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, options RestoreOptions, objects []*uplink.Object) error {
+	if len(objects) == 0 {
+		return &TransferError{Op: "restore", Key: configStorj.UploadPath + options.Key, Err: uplink.ErrObjectNotFound}
+	}
+
+	for _, object := range objects {
+		target, err := restorePath(options.Destination, configStorj.UploadPath, object.Key)
+		if err != nil {
+			return &TransferError{Op: "restore", Key: object.Key, Err: err}
+		}
+		if err = DownloadData(ctx, project, configStorj, object.Key, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runBackups returns the objects of the run with the given ID whose keys,
+// relative to the upload path, start with prefix.
+func runBackups(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, runID, prefix string) ([]*uplink.Object, error) {
+	manifest, err := LoadManifest(ctx, project, configStorj, runID)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := ManifestBackups(ctx, project, configStorj, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	fullPrefix := configStorj.UploadPath + strings.TrimPrefix(prefix, "/")
+	selected := objects[:0]
+	for _, object := range objects {
+		if strings.HasPrefix(object.Key, fullPrefix) {
+			selected = append(selected, object)
+		}
+	}
+	return selected, nil
+}
+
+// selectBackups filters the listed objects down to the ones to restore.
+// With latest set only the objects of the newest backup are kept, with a
+// non-zero at only the ones of the newest backup created at or before that
+// time. Backups are grouped like for pruning, see planPrune: by the runs of
+// the manifests, or by the entries below the upload path if the key template
+// groups runs. Objects that cannot be grouped are all kept if no backup is
+// found, as every key holds the latest version of its item then.
+func selectBackups(objects []*uplink.Object, manifests []*Manifest, configStorj ConfigStorj, latest bool, at time.Time) ([]*uplink.Object, error) {
+	var selected []*uplink.Object
+	for _, object := range objects {
+		if object.IsPrefix {
+			continue
+		}
+		if !at.IsZero() && object.System.Created.After(at) {
+			continue
+		}
+		selected = append(selected, object)
+	}
+
+	if !latest && at.IsZero() {
+		return selected, nil
+	}
+
+	keyTemplate, err := ParseKeyTemplate(configStorj.KeyTemplate)
+	if err != nil {
+		return nil, &ConfigError{Err: err}
+	}
+	var started []*Manifest
+	for _, manifest := range manifests {
+		if at.IsZero() || !manifest.Started.After(at) {
+			started = append(started, manifest)
+		}
+	}
+	backups, unrecorded, _ := groupRuns(selected, started)
+	if keyTemplate.GroupsByRun() {
+		backups = append(backups, groupBackups(unrecorded, configStorj.UploadPath)...)
+		sortBackups(backups)
+	}
+	if len(backups) == 0 {
+		return unrecorded, nil
+	}
+	return backups[0].Objects, nil
+}
+
+// restorePath returns the local file path for an object key,
+// refusing keys that would escape the destination directory.
+func restorePath(destination, uploadPath, key string) (string, error) {
+	rel := path.Clean("/" + strings.TrimPrefix(key, uploadPath))
+	rel = strings.TrimPrefix(rel, "/")
+	if rel == "" || rel == "." {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(destination, filepath.FromSlash(rel)), nil
+}
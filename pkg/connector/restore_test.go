@@ -0,0 +1,153 @@
+package connector
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"storj.io/uplink"
+)
+
+func TestRestorePath(t *testing.T) {
+	destination := filepath.FromSlash("/restore")
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "backups/db01/a.sql", want: "/restore/db01/a.sql"},
+		{key: "backups/a.sql", want: "/restore/a.sql"},
+		{key: "backups/../../etc/passwd", want: "/restore/etc/passwd"},
+		{key: "backups/db01/../../../a.sql", want: "/restore/a.sql"},
+		{key: "backups//db01/./a.sql", want: "/restore/db01/a.sql"},
+		{key: "backups/", want: ""},
+		{key: "backups/..", want: ""},
+	}
+	for _, test := range tests {
+		got, err := restorePath(destination, "backups/", test.key)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("%q: got %s, want an error", test.key, got)
+			}
+			continue
+		}
+		if err != nil || got != filepath.FromSlash(test.want) {
+			t.Errorf("%q: got %s, error %v, want %s", test.key, got, err, test.want)
+		}
+	}
+}
+
+func TestSelectBackups(t *testing.T) {
+	now := time.Date(2021, 3, 31, 12, 0, 0, 0, time.UTC)
+	object := func(key string, age time.Duration) *uplink.Object {
+		return &uplink.Object{Key: key, System: uplink.SystemMetadata{Created: now.Add(-age)}}
+	}
+	manifest := func(runID string, age time.Duration, keys ...string) *Manifest {
+		m := &Manifest{RunID: runID, Started: now.Add(-age)}
+		for _, key := range keys {
+			m.Objects = append(m.Objects, ManifestObject{Key: key})
+		}
+		return m
+	}
+	keys := func(objects []*uplink.Object) []string {
+		keys := []string{}
+		for _, object := range objects {
+			keys = append(keys, object.Key)
+		}
+		return keys
+	}
+
+	// Two runs below their timestamp, without manifests.
+	byTimestamp := []*uplink.Object{
+		object("db01/20210330T120000Z/a.sql", 24*time.Hour),
+		object("db01/20210330T120000Z/sub/c.sql", 24*time.Hour),
+		object("db01/20210331T115000Z/a.sql", 10*time.Minute),
+		object("db01/20210331T115000Z/sub/c.sql", 9*time.Minute),
+		{Key: "db01/20210331T115000Z/sub/", IsPrefix: true},
+	}
+	// Two runs with the default key template; the newer one overwrote a.sql.
+	byManifest := []*uplink.Object{
+		object("db01/a.sql", 10*time.Minute),
+		object("db01/b.sql", 24*time.Hour),
+		object("db01/sub/c.sql", 9*time.Minute),
+	}
+	manifests := []*Manifest{
+		manifest("run1", 24*time.Hour, "db01/a.sql", "db01/b.sql"),
+		manifest("run2", 10*time.Minute, "db01/a.sql", "db01/sub/c.sql"),
+	}
+
+	tests := []struct {
+		name        string
+		objects     []*uplink.Object
+		manifests   []*Manifest
+		keyTemplate string
+		latest      bool
+		at          time.Time
+		want        []string
+	}{
+		{
+			name:        "all",
+			objects:     byTimestamp,
+			keyTemplate: "{{.Timestamp}}/{{.RelPath}}",
+			want:        []string{"db01/20210330T120000Z/a.sql", "db01/20210330T120000Z/sub/c.sql", "db01/20210331T115000Z/a.sql", "db01/20210331T115000Z/sub/c.sql"},
+		},
+		{
+			name:        "latest entry",
+			objects:     byTimestamp,
+			keyTemplate: "{{.Timestamp}}/{{.RelPath}}",
+			latest:      true,
+			want:        []string{"db01/20210331T115000Z/a.sql", "db01/20210331T115000Z/sub/c.sql"},
+		},
+		{
+			name:        "entry at",
+			objects:     byTimestamp,
+			keyTemplate: "{{.Timestamp}}/{{.RelPath}}",
+			at:          now.Add(-time.Hour),
+			want:        []string{"db01/20210330T120000Z/a.sql", "db01/20210330T120000Z/sub/c.sql"},
+		},
+		{
+			name:      "latest run",
+			objects:   byManifest,
+			manifests: manifests,
+			latest:    true,
+			want:      []string{"db01/a.sql", "db01/sub/c.sql"},
+		},
+		{
+			name:      "run at",
+			objects:   byManifest,
+			manifests: manifests,
+			at:        now.Add(-time.Hour),
+			want:      []string{"db01/b.sql"},
+		},
+		{
+			name:    "latest without manifests",
+			objects: byManifest,
+			latest:  true,
+			want:    []string{"db01/a.sql", "db01/b.sql", "db01/sub/c.sql"},
+		},
+		{
+			name:    "at without manifests",
+			objects: byManifest,
+			at:      now.Add(-time.Hour),
+			want:    []string{"db01/b.sql"},
+		},
+		{
+			name:        "none at",
+			objects:     byTimestamp,
+			keyTemplate: "{{.Timestamp}}/{{.RelPath}}",
+			at:          now.Add(-48 * time.Hour),
+			want:        []string{},
+		},
+	}
+	for _, test := range tests {
+		configStorj := ConfigStorj{UploadPath: "db01/", KeyTemplate: test.keyTemplate}
+		selected, err := selectBackups(test.objects, test.manifests, configStorj, test.latest, test.at)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := keys(selected); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: selected %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package connector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"storj.io/uplink"
+)
+
+// RetentionPolicy describes which backups are kept when pruning.
+// A backup is kept if any of the rules selects it.
+type RetentionPolicy struct {
+	KeepLast    int      `json:"keepLast"`
+	KeepWithin  Duration `json:"keepWithin"`
+	KeepDaily   int      `json:"keepDaily"`
+	KeepWeekly  int      `json:"keepWeekly"`
+	KeepMonthly int      `json:"keepMonthly"`
+}
+
+// IsEmpty reports whether the policy has no rules.
+// An empty policy keeps every backup.
+func (policy RetentionPolicy) IsEmpty() bool {
+	return policy == RetentionPolicy{}
+}
+
+// backup groups the objects that belong to a single backup.
+// A backup is either the run recorded by Manifest, or, for objects not
+// recorded by any manifest, an entry directly below the upload path.
+type backup struct {
+	Name     string
+	Created  time.Time
+	Objects  []*uplink.Object
+	Manifest *Manifest
+}
+
+// groupBackups groups the objects listed below uploadPath into backups,
+// sorted from the newest to the oldest.
+func groupBackups(objects []*uplink.Object, uploadPath string) []*backup {
+	byName := make(map[string]*backup)
+	var backups []*backup
+
+	for _, object := range objects {
+		if object.IsPrefix {
+			continue
+		}
+		name := strings.SplitN(strings.TrimPrefix(object.Key, uploadPath), "/", 2)[0]
+		b, ok := byName[name]
+		if !ok {
+			b = &backup{Name: name}
+			byName[name] = b
+			backups = append(backups, b)
+		}
+		b.Objects = append(b.Objects, object)
+		if object.System.Created.After(b.Created) {
+			b.Created = object.System.Created
+		}
+	}
+
+	sortBackups(backups)
+	return backups
+}
+
+// groupRuns groups the objects into the runs of the manifests, sorted from
+// the newest to the oldest. An object belongs to the newest run that
+// uploaded its key, as that run overwrote the objects of older runs.
+// It also returns the objects not recorded by any manifest, and the
+// manifests none of whose objects are left.
+func groupRuns(objects []*uplink.Object, manifests []*Manifest) (runs []*backup, unrecorded []*uplink.Object, stale []*Manifest) {
+	sorted := append([]*Manifest(nil), manifests...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Started.After(sorted[j].Started)
+	})
+
+	byKey := make(map[string]*backup)
+	all := make([]*backup, 0, len(sorted))
+	for _, manifest := range sorted {
+		run := &backup{Name: manifest.RunID, Created: manifest.Started, Manifest: manifest}
+		all = append(all, run)
+		for _, entry := range manifest.Objects {
+			if _, ok := byKey[entry.Key]; !ok {
+				byKey[entry.Key] = run
+			}
+		}
+	}
+
+	for _, object := range objects {
+		if object.IsPrefix {
+			continue
+		}
+		run, ok := byKey[object.Key]
+		if !ok {
+			unrecorded = append(unrecorded, object)
+			continue
+		}
+		run.Objects = append(run.Objects, object)
+	}
+
+	for _, run := range all {
+		if len(run.Objects) == 0 {
+			stale = append(stale, run.Manifest)
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, unrecorded, stale
+}
+
+// sortBackups sorts the backups from the newest to the oldest.
+func sortBackups(backups []*backup) {
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].Created.After(backups[j].Created)
+	})
+}
+
+// prunePlan is the result of applying a retention policy to the backups.
+type prunePlan struct {
+	Keep   []*backup
+	Remove []*backup
+	// Stale are the manifests of runs whose objects were all overwritten
+	// or deleted since.
+	Stale []*Manifest
+	// Skipped are the objects not recorded by any manifest that cannot be
+	// grouped into backups, see planPrune.
+	Skipped []*uplink.Object
+}
+
+// planPrune groups the objects listed below the upload path into backups and
+// splits them into the ones kept and the ones removed by the policy.
+// Objects recorded by a manifest are grouped by run. The other objects are
+// only grouped into backups if the first path element of their keys
+// identifies the run, see KeyTemplate.GroupsByRun, as otherwise every file
+// or directory of a single run would be a backup of its own; they are
+// skipped then, and pruning is refused if there are no manifests at all.
+func planPrune(objects []*uplink.Object, manifests []*Manifest, configStorj ConfigStorj, policy RetentionPolicy, now time.Time) (*prunePlan, error) {
+	keyTemplate, err := ParseKeyTemplate(configStorj.KeyTemplate)
+	if err != nil {
+		return nil, &ConfigError{Err: err}
+	}
+
+	plan := &prunePlan{}
+	backups, unrecorded, stale := groupRuns(objects, manifests)
+	plan.Stale = stale
+	switch {
+	case keyTemplate.GroupsByRun():
+		backups = append(backups, groupBackups(unrecorded, configStorj.UploadPath)...)
+		sortBackups(backups)
+	case len(manifests) == 0:
+		text := configStorj.KeyTemplate
+		if text == "" {
+			text = DefaultKeyTemplate
+		}
+		return nil, &ConfigError{Err: fmt.Errorf("cannot prune: no run manifests found and keyTemplate %q does not start with a directory named after the run such as {{.Timestamp}}/ or {{.RunID}}/, so the entries below the upload path are not whole backups", text)}
+	default:
+		plan.Skipped = unrecorded
+	}
+
+	plan.Keep, plan.Remove = applyRetention(backups, policy, now)
+	return plan, nil
+}
+
+// applyRetention splits the backups, sorted from the newest to the oldest,
+// into the ones kept and the ones removed by the policy.
+func applyRetention(backups []*backup, policy RetentionPolicy, now time.Time) (keep, remove []*backup) {
+	if policy.IsEmpty() {
+		return backups, nil
+	}
+
+	within := time.Duration(policy.KeepWithin)
+
+	daily := newBucketCounter(policy.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") })
+	weekly := newBucketCounter(policy.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%02d", year, week)
+	})
+	monthly := newBucketCounter(policy.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") })
+
+	for i, b := range backups {
+		created := b.Created.UTC()
+		kept := i < policy.KeepLast
+		if within > 0 && !created.Before(now.Add(-within)) {
+			kept = true
+		}
+		// Every bucket counter has to see every backup,
+		// so these are evaluated even if the backup is already kept.
+		if daily.keep(created) {
+			kept = true
+		}
+		if weekly.keep(created) {
+			kept = true
+		}
+		if monthly.keep(created) {
+			kept = true
+		}
+
+		if kept {
+			keep = append(keep, b)
+		} else {
+			remove = append(remove, b)
+		}
+	}
+
+	return keep, remove
+}
+
+// bucketCounter keeps the newest backup of each of the last n
+// time buckets (days, weeks or months).
+type bucketCounter struct {
+	remaining int
+	last      string
+	bucket    func(time.Time) string
+}
+
+func newBucketCounter(n int, bucket func(time.Time) string) *bucketCounter {
+	return &bucketCounter{remaining: n, bucket: bucket}
+}
+
+// keep reports whether the backup created at t is the newest one of a
+// bucket that is still within the limit. Backups must be passed in from
+// the newest to the oldest.
+func (counter *bucketCounter) keep(t time.Time) bool {
+	if counter.remaining <= 0 {
+		return false
+	}
+	bucket := counter.bucket(t)
+	if bucket == counter.last {
+		return false
+	}
+	counter.last = bucket
+	counter.remaining--
+	return true
+}
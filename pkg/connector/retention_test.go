@@ -0,0 +1,183 @@
+package connector
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"storj.io/uplink"
+)
+
+func TestApplyRetention(t *testing.T) {
+	now := time.Date(2021, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	// Two backups a day for the last 70 days.
+	var objects []*uplink.Object
+	for day := 0; day < 70; day++ {
+		for _, hour := range []int{1, 13} {
+			created := now.AddDate(0, 0, -day).Truncate(24 * time.Hour).Add(time.Duration(hour) * time.Hour)
+			if created.After(now) {
+				continue
+			}
+			objects = append(objects, &uplink.Object{
+				Key:    "backups/" + created.Format("2006-01-02T15") + "/file.txt",
+				System: uplink.SystemMetadata{Created: created},
+			})
+		}
+	}
+	backups := groupBackups(objects, "backups/")
+
+	names := func(backups []*backup) []string {
+		var result []string
+		for _, b := range backups {
+			result = append(result, b.Name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		keep   []string
+	}{
+		{
+			name:   "keep last",
+			policy: RetentionPolicy{KeepLast: 3},
+			keep:   []string{"2021-03-31T01", "2021-03-30T13", "2021-03-30T01"},
+		},
+		{
+			name:   "keep within",
+			policy: RetentionPolicy{KeepWithin: Duration(24 * time.Hour)},
+			keep:   []string{"2021-03-31T01", "2021-03-30T13"},
+		},
+		{
+			name:   "keep daily",
+			policy: RetentionPolicy{KeepDaily: 2},
+			keep:   []string{"2021-03-31T01", "2021-03-30T13"},
+		},
+		{
+			name:   "keep monthly",
+			policy: RetentionPolicy{KeepMonthly: 3},
+			keep:   []string{"2021-03-31T01", "2021-02-28T13", "2021-01-31T13"},
+		},
+		{
+			name:   "grandfather father son",
+			policy: RetentionPolicy{KeepDaily: 1, KeepWeekly: 2, KeepMonthly: 2},
+			keep:   []string{"2021-03-31T01", "2021-03-28T13", "2021-02-28T13"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keep, remove := applyRetention(backups, test.policy, now)
+			if got := names(keep); !reflect.DeepEqual(got, test.keep) {
+				t.Errorf("kept %v, want %v", got, test.keep)
+			}
+			if len(keep)+len(remove) != len(backups) {
+				t.Errorf("kept %d and removed %d of %d backups", len(keep), len(remove), len(backups))
+			}
+		})
+	}
+
+	keep, remove := applyRetention(backups, RetentionPolicy{}, now)
+	if len(keep) != len(backups) || len(remove) != 0 {
+		t.Errorf("empty policy removed %d backups", len(remove))
+	}
+}
+
+func TestPlanPrune(t *testing.T) {
+	now := time.Date(2021, 3, 31, 12, 0, 0, 0, time.UTC)
+	object := func(key string, age time.Duration) *uplink.Object {
+		return &uplink.Object{Key: key, System: uplink.SystemMetadata{Created: now.Add(-age)}}
+	}
+	policy := RetentionPolicy{KeepLast: 1}
+
+	// A single run stored with the default key template, without a manifest.
+	objects := []*uplink.Object{
+		object("db01/a.sql", 3*time.Minute),
+		object("db01/b.sql", 2*time.Minute),
+		object("db01/sub/c.sql", time.Minute),
+	}
+	for _, keyTemplate := range []string{"", DefaultKeyTemplate, "{{.Host}}/{{.Date}}/{{.RelPath}}"} {
+		plan, err := planPrune(objects, nil, ConfigStorj{UploadPath: "db01/", KeyTemplate: keyTemplate}, policy, now)
+		var configErr *ConfigError
+		if !errors.As(err, &configErr) || plan != nil {
+			t.Errorf("%q: plan %+v, error %v", keyTemplate, plan, err)
+		}
+	}
+
+	// Two runs stored below their timestamp.
+	objects = []*uplink.Object{
+		object("db01/20210330T120000Z/a.sql", 24*time.Hour),
+		object("db01/20210330T120000Z/sub/c.sql", 24*time.Hour),
+		object("db01/20210331T115000Z/a.sql", 10*time.Minute),
+		object("db01/20210331T115000Z/sub/c.sql", 10*time.Minute),
+	}
+	plan, err := planPrune(objects, nil, ConfigStorj{UploadPath: "db01/", KeyTemplate: "{{.Timestamp}}/{{.RelPath}}"}, policy, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Keep) != 1 || plan.Keep[0].Name != "20210331T115000Z" || len(plan.Keep[0].Objects) != 2 {
+		t.Errorf("kept %+v", plan.Keep)
+	}
+	if len(plan.Remove) != 1 || plan.Remove[0].Name != "20210330T120000Z" || len(plan.Remove[0].Objects) != 2 {
+		t.Errorf("removed %+v", plan.Remove)
+	}
+}
+
+func TestPlanPruneManifests(t *testing.T) {
+	now := time.Date(2021, 3, 31, 12, 0, 0, 0, time.UTC)
+	object := func(key string, age time.Duration) *uplink.Object {
+		return &uplink.Object{Key: key, System: uplink.SystemMetadata{Created: now.Add(-age)}}
+	}
+	manifest := func(runID string, age time.Duration, keys ...string) *Manifest {
+		m := &Manifest{RunID: runID, Started: now.Add(-age)}
+		for _, key := range keys {
+			m.Objects = append(m.Objects, ManifestObject{Key: key})
+		}
+		return m
+	}
+
+	// Three runs with the default key template, each overwriting the
+	// objects of the previous one.
+	manifests := []*Manifest{
+		manifest("run2", 24*time.Hour, "db01/a.sql", "db01/b.sql"),
+		manifest("run3", time.Minute, "db01/a.sql", "db01/b.sql", "db01/sub/c.sql"),
+		manifest("run1", 48*time.Hour, "db01/a.sql", "db01/old.sql"),
+	}
+	objects := []*uplink.Object{
+		object("db01/a.sql", time.Minute),
+		object("db01/b.sql", time.Minute),
+		object("db01/legacy.sql", 72*time.Hour),
+		object("db01/old.sql", 48*time.Hour),
+		object("db01/sub/c.sql", time.Minute),
+	}
+
+	plan, err := planPrune(objects, manifests, ConfigStorj{UploadPath: "db01/"}, RetentionPolicy{KeepLast: 1}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := func(b *backup) []string {
+		var keys []string
+		for _, object := range b.Objects {
+			keys = append(keys, object.Key)
+		}
+		return keys
+	}
+	if len(plan.Keep) != 1 || plan.Keep[0].Manifest != manifests[1] ||
+		!reflect.DeepEqual(keys(plan.Keep[0]), []string{"db01/a.sql", "db01/b.sql", "db01/sub/c.sql"}) {
+		t.Errorf("kept %+v", plan.Keep)
+	}
+	if len(plan.Remove) != 1 || plan.Remove[0].Manifest != manifests[2] ||
+		!reflect.DeepEqual(keys(plan.Remove[0]), []string{"db01/old.sql"}) {
+		t.Errorf("removed %+v", plan.Remove)
+	}
+	if len(plan.Stale) != 1 || plan.Stale[0] != manifests[0] {
+		t.Errorf("stale %+v", plan.Stale)
+	}
+	if len(plan.Skipped) != 1 || plan.Skipped[0].Key != "db01/legacy.sql" {
+		t.Errorf("skipped %+v", plan.Skipped)
+	}
+}
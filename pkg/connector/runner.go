@@ -0,0 +1,108 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/source"
+)
+
+// Runner performs a complete backup run: it uploads every item of a source
+// to the configured bucket, stores the manifest of the run and optionally
+// prunes old backups and prints a restricted shareable access afterwards.
+type Runner struct {
+	// Config is the Storj configuration the backup is uploaded with.
+	Config ConfigStorj
+	// UseAccessKey connects using the serialized access instead of the API key.
+	UseAccessKey bool
+	// SourceName is the name the source is registered under.
+	SourceName string
+	// Source produces the items to back up. It is not closed by the runner.
+	Source source.Source
+	// Prune applies the configured retention policy after the upload.
+	Prune bool
+	// Share prints a restricted shareable access after the upload.
+	Share bool
+	// Session, if set, is used instead of opening a new session.
+	// It is left open for the caller to reuse and close.
+	Session *Session
+}
+
+// Run uploads every item of the source over a single session.
+func (runner *Runner) Run(ctx context.Context) (err error) {
+
+	defer trace(ctx, "Run")()
+
+	// Object keys are rendered from the configured template for this run.
+	run := NewRun(runner.SourceName)
+	keyTemplate, err := ParseKeyTemplate(runner.Config.KeyTemplate)
+	if err != nil {
+		return &ConfigError{Err: err}
+	}
+
+	// Connect to storj network using the specified credentials.
+	session := runner.Session
+	if session == nil {
+		session, err = OpenSession(ctx, runner.Config, runner.UseAccessKey)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := session.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}()
+	}
+
+	// Abort the interrupted uploads that were not resumed in time, as they are billed while pending.
+	// Failing to do so does not affect this run, so it is retried by the next one.
+	if err := cleanupUploads(ctx, uplinkProject{session.Project}, runner.Config.Multipart, time.Now()); err != nil {
+		fmt.Fprintf(progress(ctx), "Could not clean up interrupted uploads: %v\n", err)
+	}
+
+	uploader := Uploader{
+		Session:     session,
+		Config:      runner.Config,
+		SourceName:  runner.SourceName,
+		Source:      runner.Source,
+		KeyTemplate: keyTemplate,
+		Run:         run,
+		Workers:     runner.Config.Workers,
+	}
+
+	// Stream the items of the source to the upload workers.
+	fmt.Fprintf(progress(ctx), "Initiating back-up.\n")
+	report, sourceErr := uploadSource(ctx, &uploader)
+	report.Print(progress(ctx))
+
+	// Record the objects of the run, even if some failed, so that they can be restored together.
+	var manifestErr error
+	if len(report.Succeeded) > 0 {
+		manifestErr = UploadManifest(ctx, session.Project, runner.Config, NewManifest(runner.Config, run, report, time.Now()))
+	}
+	if err = errs.Combine(sourceErr, report.Err(), manifestErr); err != nil {
+		return err
+	}
+	fmt.Fprintf(progress(ctx), "Back-up complete.\n\n")
+
+	// Apply the retention policy if requested.
+	if runner.Prune {
+		if err = PruneBackups(ctx, session.Project, runner.Config, runner.Config.Retention, false); err != nil {
+			return err
+		}
+	}
+
+	// Create restricted shareable serialized access to the uploaded objects if requested.
+	if runner.Share {
+		keys := make([]string, 0, len(report.Succeeded))
+		for _, result := range report.Succeeded {
+			keys = append(keys, ObjectKey(runner.Config, result.Key))
+		}
+		return ShareAccess(ctx, session.Access, runner.Config, keys...)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"storj.io/uplink"
+)
+
+// Session is an open connection to the Storj network.
+// It owns the access and the project, which are shared by every
+// operation of a run, and must be closed by the caller.
+type Session struct {
+	Access  *uplink.Access
+	Project *uplink.Project
+}
+
+// OpenSession connects to the desired Storj network using the API key,
+// or the serialized access if accesskey is set, and ensures the
+// configured bucket exists.
+func OpenSession(ctx context.Context, configStorj ConfigStorj, accesskey bool) (_ *Session, err error) {
+
+	defer trace(ctx, "OpenSession")()
+
+	var access *uplink.Access
+	var cfg uplink.Config
+
+	// Configure the UserAgent
+	/* For a list of valid User Agents, refer to */
+	cfg.UserAgent = ""
+
+	if accesskey {
+		fmt.Fprintln(progress(ctx), "Connecting to Storj network using Serialized access.")
+		// Generate access handle using serialized access.
+		access, err = uplink.ParseAccess(configStorj.SerializedAccess)
+		if err != nil {
+			return nil, &ConnectError{Err: err}
+		}
+	} else {
+		fmt.Fprintln(progress(ctx), "Connecting to Storj network.")
+		// Generate access handle using API key, satellite url and encryption passphrase.
+		access, err = cfg.RequestAccessWithPassphrase(ctx, configStorj.Satellite, configStorj.APIKey, configStorj.EncryptionPassphrase)
+		if err != nil {
+			return nil, &ConnectError{Err: err}
+		}
+	}
+
+	// Open a new project, which stays open until the session is closed.
+	project, err := cfg.OpenProject(ctx, access)
+	if err != nil {
+		return nil, &ConnectError{Err: err}
+	}
+	defer func() {
+		if err != nil {
+			_ = project.Close()
+		}
+	}()
+
+	// Ensure the desired Bucket within the Project
+	_, err = project.EnsureBucket(ctx, configStorj.Bucket)
+	if err != nil {
+		return nil, &ConnectError{Err: err}
+	}
+
+	return &Session{Access: access, Project: project}, nil
+}
+
+// Close closes the project of the session.
+func (session *Session) Close() error {
+	if err := session.Project.Close(); err != nil {
+		return &ConnectError{Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"storj.io/uplink"
+)
+
+// DefaultLinkshareURL is the linksharing service of the Storj network
+// shared objects are linked to by default.
+const DefaultLinkshareURL = "https://link.us1.storjshare.io"
+
+// SharedAccess is a restricted serialized access to a single object or prefix.
+type SharedAccess struct {
+	// Access is the serialized access grant.
+	Access string `json:"access"`
+	// Bucket and Prefix are the bucket and the object key or prefix shared.
+	Bucket string `json:"bucket"`
+	Prefix string `json:"prefix"`
+	// IsPrefix reports whether Prefix is a prefix rather than an object key.
+	IsPrefix bool `json:"isPrefix"`
+
+	AllowDownload bool `json:"allowDownload"`
+	AllowUpload   bool `json:"allowUpload"`
+	AllowList     bool `json:"allowList"`
+	AllowDelete   bool `json:"allowDelete"`
+	// NotBefore and NotAfter limit the validity of the access, if set.
+	NotBefore Time `json:"notBefore"`
+	NotAfter  Time `json:"notAfter"`
+}
+
+// LinkshareURL returns the URL of the shared object or prefix on the
+// linksharing service at baseURL. The URL embeds the serialized access.
+func (shared *SharedAccess) LinkshareURL(baseURL string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/s/" + url.PathEscape(shared.Access) + "/" +
+		url.PathEscape(shared.Bucket) + "/" + escapeKey(shared.Prefix)
+}
+
+// escapeKey escapes every slash separated component of an object key.
+func escapeKey(key string) string {
+	components := strings.Split(key, "/")
+	for i, component := range components {
+		components[i] = url.PathEscape(component)
+	}
+	return strings.Join(components, "/")
+}
+
+// ShareBackup creates a restricted serialized access to the existing object
+// or prefix at key, relative to the upload path, with the given permission.
+// An empty key shares the whole upload path.
+func ShareBackup(ctx context.Context, project *uplink.Project, access *uplink.Access, configStorj ConfigStorj, key string, permission uplink.Permission) (*SharedAccess, error) {
+
+	defer trace(ctx, "ShareBackup")()
+
+	objects, err := ListBackups(ctx, project, configStorj, key, false)
+	if err != nil {
+		return nil, err
+	}
+	fullKey := configStorj.UploadPath + strings.TrimPrefix(key, "/")
+	if len(objects) == 0 {
+		return nil, &TransferError{Op: "share", Key: fullKey, Err: errors.New("no backup found")}
+	}
+
+	prefix := uplink.SharePrefix{Bucket: configStorj.Bucket, Prefix: fullKey}
+	isPrefix := len(objects) != 1 || objects[0].IsPrefix || objects[0].Key != fullKey
+	if isPrefix && fullKey != "" && !strings.HasSuffix(fullKey, "/") {
+		prefix.Prefix += "/"
+	}
+
+	serializedAccess, err := shareAccess(access, permission, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SharedAccess{
+		Access:        serializedAccess,
+		Bucket:        prefix.Bucket,
+		Prefix:        prefix.Prefix,
+		IsPrefix:      isPrefix,
+		AllowDownload: permission.AllowDownload,
+		AllowUpload:   permission.AllowUpload,
+		AllowList:     permission.AllowList,
+		AllowDelete:   permission.AllowDelete,
+		NotBefore:     Time{Time: permission.NotBefore},
+		NotAfter:      Time{Time: permission.NotAfter},
+	}, nil
+}
+
+// shareAccess returns the serialized access restricted to permission and prefixes.
+func shareAccess(access *uplink.Access, permission uplink.Permission, prefixes ...uplink.SharePrefix) (string, error) {
+	sharedAccess, err := access.Share(permission, prefixes...)
+	if err != nil {
+		return "", &TransferError{Op: "share", Err: err}
+	}
+
+	serializedAccess, err := sharedAccess.Serialize()
+	if err != nil {
+		return "", &TransferError{Op: "share", Err: err}
+	}
+	return serializedAccess, nil
+}
@@ -0,0 +1,14 @@
+package connector
+
+import (
+	"testing"
+)
+
+func TestLinkshareURL(t *testing.T) {
+	shared := SharedAccess{Access: "1Abc", Bucket: "backups", Prefix: "db01/dump 1.sql"}
+
+	want := "https://link.us1.storjshare.io/s/1Abc/backups/db01/dump%201.sql"
+	if got := shared.LinkshareURL(DefaultLinkshareURL + "/"); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
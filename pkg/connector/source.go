@@ -0,0 +1,85 @@
+// Module to connect to a `source` instance
+// and fetch data to be uploaded.
+
+package connector
+
+import (
+	"context"
+	"io"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/source"
+)
+
+// LoadSource creates the source registered under sourceName
+// and configures it from the given JSON, YAML or TOML configuration file,
+// or from the source section of a combined configuration file.
+// The source is always configured with JSON.
+// Sources implementing source.Describer can have every field of their
+// configuration overridden by environment variables prefixed by SourceEnvPrefix.
+func LoadSource(ctx context.Context, sourceName string, fullFileName string) (source.Source, error) {
+	defer trace(ctx, "LoadSource")()
+
+	src, err := source.New(sourceName)
+	if err != nil {
+		return nil, &SourceError{Source: sourceName, Err: err}
+	}
+
+	data, err := readConfigFile(fullFileName)
+	if err != nil {
+		return nil, &ConfigError{Path: fullFileName, Err: err}
+	}
+	if data, err = configSection(data, SourceSection); err != nil {
+		return nil, &ConfigError{Path: fullFileName, Err: err}
+	}
+
+	// Environment variables override the values of the file for sources
+	// describing the type of their configuration.
+	if describer, ok := src.(source.Describer); ok {
+		if data, err = applyEnv(data, SourceEnvPrefix(sourceName), describer.Describe()); err != nil {
+			return nil, &ConfigError{Path: fullFileName, Err: err}
+		}
+	}
+
+	if err = src.Configure(data); err != nil {
+		return nil, &ConfigError{Path: fullFileName, Err: err}
+	}
+
+	return src, nil
+}
+
+// ConnectToSource enumerates the items of the source
+// that are to be uploaded.
+func ConnectToSource(ctx context.Context, sourceName string, src source.Source) ([]source.Item, error) {
+	defer trace(ctx, "ConnectToSource")()
+
+	items, err := src.Items(ctx)
+	if err != nil {
+		return nil, &SourceError{Source: sourceName, Err: err}
+	}
+
+	return items, nil
+}
+
+// StreamSource sends the items of the source that are to be uploaded on items,
+// while they are being enumerated if the source supports it.
+// It does not close the channel.
+func StreamSource(ctx context.Context, sourceName string, src source.Source, items chan<- source.Item) error {
+	defer trace(ctx, "StreamSource")()
+
+	if err := source.Stream(ctx, src, items); err != nil {
+		return &SourceError{Source: sourceName, Err: err}
+	}
+
+	return nil
+}
+
+// OpenSourceItem returns the reader of a single source item.
+// UploadData reads it sequentially, so it can be any stream, e.g. a pipe.
+func OpenSourceItem(ctx context.Context, sourceName string, src source.Source, item source.Item) (io.ReadCloser, error) {
+	reader, err := src.Open(ctx, item)
+	if err != nil {
+		return nil, &SourceError{Source: sourceName, Err: err}
+	}
+
+	return reader, nil
+}
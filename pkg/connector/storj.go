@@ -0,0 +1,517 @@
+// Copyright (C) 2019 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package connector implements the backup pipeline of the connector framework:
+// loading the Storj configuration, connecting to the Storj network and
+// uploading, listing, restoring and pruning backups produced by a source.
+//
+// It can be embedded in other Go programs without the cobra CLI in package cmd.
+// The Runner type wires the functions of this package into a complete backup run.
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zeebo/errs"
+	"storj.io/uplink"
+)
+
+// ConfigStorj depicts keys to search for within the stroj_config.json file.
+// Fields tagged as sensitive are redacted when the configuration is printed.
+type ConfigStorj struct {
+	APIKey               string          `json:"apikey" sensitive:"true"`
+	Satellite            string          `json:"satellite"`
+	Bucket               string          `json:"bucket"`
+	UploadPath           string          `json:"uploadPath"`
+	EncryptionPassphrase string          `json:"encryptionpassphrase" sensitive:"true"`
+	SerializedAccess     string          `json:"serializedAccess" sensitive:"true"`
+	AllowDownload        Bool            `json:"allowDownload"`
+	AllowUpload          Bool            `json:"allowUpload"`
+	AllowList            Bool            `json:"allowList"`
+	AllowDelete          Bool            `json:"allowDelete"`
+	NotBefore            Time            `json:"notBefore"`
+	NotAfter             Time            `json:"notAfter"`
+	SharePrefixes        []string        `json:"sharePrefixes"`
+	KeyTemplate          string          `json:"keyTemplate"`
+	Compression          string          `json:"compression"`
+	Workers              int             `json:"workers"`
+	Retention            RetentionPolicy `json:"retention"`
+	Multipart            MultipartPolicy `json:"multipart"`
+	// EncryptionRecipients are the age public keys the data is encrypted to,
+	// and EncryptionIdentityFile the age identity file used to decrypt it.
+	EncryptionRecipients   []string `json:"encryptionRecipients"`
+	EncryptionIdentityFile string   `json:"encryptionIdentityFile"`
+}
+
+// LoadStorjConfiguration reads and parses the JSON, YAML or TOML file that contain Storj configuration information.
+// In a combined configuration file the storj section is read.
+// Invalid fields are reported together in a *ValidationError.
+// Every field can be overridden by an environment variable listed by EnvVars(StorjEnvPrefix, ConfigStorj{}),
+// or by a file named by the same variable suffixed with _FILE.
+// A configuration holding profiles is read as the default profile.
+func LoadStorjConfiguration(ctx context.Context, fullFileName string) (ConfigStorj, error) {
+	return LoadStorjProfile(ctx, fullFileName, "")
+}
+
+// LoadStorjProfile reads the named profile of a Storj configuration holding
+// profiles like LoadStorjConfiguration. An empty profileName selects the
+// profile named by the STORJ_PROFILE environment variable, the defaultProfile
+// field or the profile named default, in that order.
+func LoadStorjProfile(ctx context.Context, fullFileName string, profileName string) (ConfigStorj, error) {
+
+	defer trace(ctx, "LoadStorjProfile")()
+
+	var configStorj ConfigStorj
+	data, err := readConfigFile(fullFileName)
+	if err != nil {
+		return configStorj, &ConfigError{Path: fullFileName, Err: err}
+	}
+	if data, err = configSection(data, StorjSection); err != nil {
+		return configStorj, &ConfigError{Path: fullFileName, Err: err}
+	}
+	if data, err = selectProfile(data, profileName); err != nil {
+		return configStorj, &ConfigError{Path: fullFileName, Err: err}
+	}
+
+	// Environment variables override the values of the file.
+	if data, err = applyEnv(data, StorjEnvPrefix, configStorj); err != nil {
+		return configStorj, &ConfigError{Path: fullFileName, Err: err}
+	}
+
+	// Reject an invalid configuration before connecting.
+	if err = decodeFields(data, &configStorj); err != nil {
+		return configStorj, &ConfigError{Path: fullFileName, Err: err}
+	}
+
+	// Convert the upload path to standard form.
+	if configStorj.UploadPath != "" {
+		if configStorj.UploadPath == "/" {
+			configStorj.UploadPath = ""
+		} else {
+			checkSlash := configStorj.UploadPath[len(configStorj.UploadPath)-1:]
+			if checkSlash != "/" {
+				configStorj.UploadPath = configStorj.UploadPath + "/"
+			}
+		}
+	}
+
+	return configStorj, nil
+}
+
+// ShareAccess generates and prints the shareable serialized access
+// as per the restrictions provided by the user.
+// The access is restricted to the prefixes returned by SharePrefixes for the uploaded object keys.
+func ShareAccess(ctx context.Context, access *uplink.Access, configStorj ConfigStorj, keys ...string) error {
+
+	defer trace(ctx, "ShareAccess")()
+
+	prefixes, err := SharePrefixes(configStorj, keys)
+	if err != nil {
+		return &ConfigError{Err: err}
+	}
+
+	permission := uplink.Permission{
+		AllowDownload: bool(configStorj.AllowDownload),
+		AllowUpload:   bool(configStorj.AllowUpload),
+		AllowList:     bool(configStorj.AllowList),
+		AllowDelete:   bool(configStorj.AllowDelete),
+		NotBefore:     configStorj.NotBefore.Time,
+		NotAfter:      configStorj.NotAfter.Time,
+	}
+
+	// Generate restricted serialized access.
+	serializedAccess, err := shareAccess(access, permission, prefixes...)
+	if err != nil {
+		return err
+	}
+
+	for _, prefix := range prefixes {
+		fmt.Fprintln(progress(ctx), "Shared prefix: ", "sj://"+prefix.Bucket+"/"+prefix.Prefix)
+	}
+	fmt.Fprintln(progress(ctx), "Shareable serialized access: ", serializedAccess)
+	return nil
+}
+
+// SharePrefixes returns the prefixes shared by ShareAccess: the object key if
+// a single key was uploaded, the upload path of the bucket otherwise, and the
+// sharePrefixes of the configuration. A share prefix is a bucket optionally
+// followed by a slash and an object key prefix, e.g. logs or sj://logs/db01/.
+func SharePrefixes(configStorj ConfigStorj, keys []string) ([]uplink.SharePrefix, error) {
+	prefix := uplink.SharePrefix{Bucket: configStorj.Bucket, Prefix: configStorj.UploadPath}
+	if len(keys) == 1 {
+		prefix.Prefix = keys[0]
+	}
+	prefixes := []uplink.SharePrefix{prefix}
+
+	for _, sharePrefix := range configStorj.SharePrefixes {
+		prefix, err := parseSharePrefix(sharePrefix)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+// parseSharePrefix parses a bucket optionally followed by a slash
+// and an object key prefix, with an optional sj:// scheme.
+func parseSharePrefix(sharePrefix string) (uplink.SharePrefix, error) {
+	bucketAndPrefix := strings.TrimPrefix(sharePrefix, "sj://")
+	bucket := bucketAndPrefix
+	prefix := ""
+	if i := strings.Index(bucketAndPrefix, "/"); i >= 0 {
+		bucket, prefix = bucketAndPrefix[:i], bucketAndPrefix[i+1:]
+	}
+	if bucket == "" {
+		return uplink.SharePrefix{}, fmt.Errorf("share prefix %q has no bucket", sharePrefix)
+	}
+	return uplink.SharePrefix{Bucket: bucket, Prefix: prefix}, nil
+}
+
+// ObjectKey returns the object key of the slash separated name relative to the upload path.
+func ObjectKey(configStorj ConfigStorj, name string) string {
+	return configStorj.UploadPath + strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "/")
+}
+
+// UploadData uploads the backup data read from reader to storj network.
+// The uploadFileName is the slash separated object name relative to the upload path.
+// The reader is read sequentially, so pipes and network streams can be uploaded directly;
+// size is the expected size of the data in bytes, or -1 if unknown.
+// A reader implementing io.Closer is closed once the upload is finished; on failure the upload is aborted.
+// The checksum of the data is recorded in the custom metadata of the object.
+func UploadData(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, uploadFileName string, reader io.Reader, size int64) error {
+	_, err := uploadData(ctx, uplinkProject{project}, configStorj, uploadFileName, reader, sourceInfo{Size: size})
+	return err
+}
+
+// uploadData implements UploadData for the item described by info and
+// returns the checksum of the uploaded data.
+func uploadData(ctx context.Context, project uploadProject, configStorj ConfigStorj, uploadFileName string, reader io.Reader, info sourceInfo) (checksum Checksum, err error) {
+
+	defer trace(ctx, "UploadData")()
+
+	key := ObjectKey(configStorj, uploadFileName)
+	size := info.Size
+
+	// Close the reader after reading from it.
+	if closer, ok := reader.(io.Closer); ok {
+		defer func() {
+			if closeErr := closer.Close(); closeErr != nil && err == nil {
+				err = &TransferError{Op: "upload", Key: key, Err: closeErr}
+			}
+		}()
+	}
+
+	// Data is compressed by the transforms selected in the configuration.
+	chain, err := UploadTransforms(configStorj)
+	if err != nil {
+		return checksum, &ConfigError{Err: err}
+	}
+
+	// Create an upload handle, uploading large items in parts.
+	upload, err := beginUpload(ctx, project, configStorj, key, info)
+	if err != nil {
+		return checksum, &TransferError{Op: "upload", Key: key, Err: err}
+	}
+	if size >= 0 {
+		fmt.Fprintf(progress(ctx), "Uploading %s (%d bytes) to %s...\n", key, size, configStorj.Bucket)
+	} else {
+		fmt.Fprintf(progress(ctx), "Uploading %s to %s...\n", key, configStorj.Bucket)
+	}
+
+	transformed, err := newTransformWriter(upload, chain)
+	if err != nil {
+		abortErr := upload.Abort()
+		return checksum, &TransferError{Op: "upload", Key: key, Err: errs.Combine(err, abortErr)}
+	}
+	// The checksum is computed over the original data, before the transforms.
+	writer := newChecksumWriter(transformed)
+
+	// ****Add the code here to create the reader for the file to be uploaded****
+
+	/* To directly copy the complete data to storj network, uncomment this code
+	and remvove/comment the section reader code snippet.
+
+	_, err = io.Copy(writer, reader)
+	if err != nil {
+		abortErr := upload.Abort()
+		return &TransferError{Op: "upload", Key: key, Err: errs.Combine(err, abortErr)}
+	}
+
+	*/
+
+	// To implement uploading in parts, use the following approcach.
+	// This approach reads the data sequentially in a buffer with specified size
+	// and uploads the corresponding data in sections.
+
+	// A failed read or write, or a size different from the expected one,
+	// aborts the upload instead of committing a truncated object.
+	written, err := dataProcessingAndCopy(writer, reader)
+	if err == nil {
+		err = checkSize(written, size)
+	}
+	var copyErr *CopyError
+	if errors.As(err, &copyErr) {
+		copyErr.Expected = size
+	}
+	if err != nil {
+		abortErr := upload.Abort()
+		return checksum, &TransferError{Op: "upload", Key: key, Err: errs.Combine(err, abortErr)}
+	}
+
+	// Flush the transforms and record them with the checksum,
+	// so that a restore can reverse them and the data can be verified.
+	err = transformed.Close()
+	if err == nil {
+		checksum = writer.Checksum()
+		metadata := checksum.Metadata()
+		for key, value := range transformMetadata(chain) {
+			metadata[key] = value
+		}
+		err = upload.SetCustomMetadata(ctx, metadata)
+	}
+	if err != nil {
+		abortErr := upload.Abort()
+		return checksum, &TransferError{Op: "upload", Key: key, Err: errs.Combine(err, abortErr)}
+	}
+
+	/*	In case you have passed a byte array(buffer) to be uploaded,
+		comment the Copy Function block and use the following approach.
+		This approach creates a reader for 32KB section starting from the current position,
+		copies the 32KB buffer data and updaes the current position.
+
+		var lastIndex = 0
+		var buf = make([]byte, 32768)
+
+		// Loop to read the backup file in chunks and append the contents to the upload object.
+		for lastIndex < int(len(dataToUpload)) {
+			reader := bytes.NewBuffer(dataToUpload[lastIndex:min(lastIndex+cap(buf), len(dataToUpload))])
+
+			_, err = io.Copy(upload, reader)
+
+			lastIndex = lastIndex + cap(buf)
+		}
+
+	*/
+
+	// Commit the upload after copying the complete content of the backup file to upload object.
+	fmt.Fprintln(progress(ctx), "Please wait while the upload is being committed to Storj.")
+	err = upload.Commit()
+	if err != nil {
+		abortErr := upload.Abort()
+		return checksum, &TransferError{Op: "upload", Key: key, Err: errs.Combine(err, abortErr)}
+	}
+
+	return checksum, nil
+}
+
+// ListBackups returns the objects stored under the upload path
+// whose keys start with the given prefix.
+// A prefix that names an existing object returns only that object.
+// The manifests below ManifestPrefix are skipped unless the prefix is below it.
+func ListBackups(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, prefix string, recursive bool) ([]*uplink.Object, error) {
+
+	defer trace(ctx, "ListBackups")()
+
+	fullPrefix := configStorj.UploadPath + strings.TrimPrefix(prefix, "/")
+
+	// A prefix not ending with a slash may be the key of a single object.
+	if fullPrefix != "" && !strings.HasSuffix(fullPrefix, "/") {
+		object, err := project.StatObject(ctx, configStorj.Bucket, fullPrefix)
+		if err == nil {
+			return []*uplink.Object{object}, nil
+		}
+		if !errors.Is(err, uplink.ErrObjectNotFound) {
+			return nil, &TransferError{Op: "list", Key: fullPrefix, Err: err}
+		}
+		fullPrefix += "/"
+	}
+
+	var objects []*uplink.Object
+	iterator := project.ListObjects(ctx, configStorj.Bucket, &uplink.ListObjectsOptions{
+		Prefix:    fullPrefix,
+		Recursive: recursive,
+		System:    true,
+		Custom:    true,
+	})
+	// The manifests of the runs are not backups, unless explicitly listed.
+	skipManifests := !isManifestKey(configStorj, fullPrefix)
+	for iterator.Next() {
+		if skipManifests && isManifestKey(configStorj, iterator.Item().Key) {
+			continue
+		}
+		objects = append(objects, iterator.Item())
+	}
+	if err := iterator.Err(); err != nil {
+		return nil, &TransferError{Op: "list", Key: fullPrefix, Err: err}
+	}
+
+	return objects, nil
+}
+
+// DownloadData downloads the object stored under key
+// and writes it to the destination file.
+func DownloadData(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, key string, destination string) error {
+
+	defer trace(ctx, "DownloadData")()
+
+	// Create a download handle.
+	download, err := project.DownloadObject(ctx, configStorj.Bucket, key, nil)
+	if err != nil {
+		return &TransferError{Op: "download", Key: key, Err: err}
+	}
+	fmt.Fprintf(progress(ctx), "Downloading %s from %s to %s...\n", key, configStorj.Bucket, destination)
+
+	// Reverse the transforms recorded when uploading, e.g. decompress the data.
+	reader, err := newTransformReader(download, download.Info().Custom, configStorj)
+	if err != nil {
+		_ = download.Close()
+		return &TransferError{Op: "download", Key: key, Err: err}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(destination), 0700); err != nil {
+		_ = errs.Combine(reader.Close(), download.Close())
+		return &TransferError{Op: "download", Key: key, Err: err}
+	}
+
+	fileWriter, err := os.OpenFile(filepath.Clean(destination), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		_ = errs.Combine(reader.Close(), download.Close())
+		return &TransferError{Op: "download", Key: key, Err: err}
+	}
+
+	// Stream the object contents to the destination file.
+	_, err = io.Copy(fileWriter, reader)
+	err = errs.Combine(err, reader.Close(), download.Close(), fileWriter.Close())
+	if err != nil {
+		return &TransferError{Op: "download", Key: key, Err: err}
+	}
+
+	return nil
+}
+
+// PruneBackups deletes the backups below the upload path that are not kept by the policy.
+// In dry-run mode the backups that would be deleted are only printed.
+func PruneBackups(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, policy RetentionPolicy, dryRun bool) error {
+
+	defer trace(ctx, "PruneBackups")()
+
+	if policy.IsEmpty() {
+		fmt.Fprintln(progress(ctx), "No retention rules configured, keeping all backups.")
+		return nil
+	}
+
+	objects, err := ListBackups(ctx, project, configStorj, "", true)
+	if err != nil {
+		return err
+	}
+	manifests, err := ListManifests(ctx, project, configStorj)
+	if err != nil {
+		return err
+	}
+
+	plan, err := planPrune(objects, manifests, configStorj, policy, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if len(plan.Skipped) > 0 {
+		fmt.Fprintf(progress(ctx), "Skipping %d object(s) not recorded by any run manifest.\n", len(plan.Skipped))
+	}
+
+	for _, b := range plan.Remove {
+		if dryRun {
+			fmt.Fprintf(progress(ctx), "Would delete backup %s created %s (%d objects).\n", b.Name, b.Created.Format(time.RFC3339), len(b.Objects))
+			continue
+		}
+		fmt.Fprintf(progress(ctx), "Deleting backup %s created %s (%d objects).\n", b.Name, b.Created.Format(time.RFC3339), len(b.Objects))
+		for _, object := range b.Objects {
+			if _, err := project.DeleteObject(ctx, configStorj.Bucket, object.Key); err != nil {
+				return &TransferError{Op: "delete", Key: object.Key, Err: err}
+			}
+		}
+		if b.Manifest != nil {
+			if err := deleteManifest(ctx, project, configStorj, b.Manifest); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, manifest := range plan.Stale {
+		if dryRun {
+			fmt.Fprintf(progress(ctx), "Would delete manifest of run %s, none of its objects are left.\n", manifest.RunID)
+			continue
+		}
+		fmt.Fprintf(progress(ctx), "Deleting manifest of run %s, none of its objects are left.\n", manifest.RunID)
+		if err := deleteManifest(ctx, project, configStorj, manifest); err != nil {
+			return err
+		}
+	}
+
+	if dryRun {
+		fmt.Fprintf(progress(ctx), "Would keep %d backup(s) and prune %d backup(s).\n", len(plan.Keep), len(plan.Remove))
+		return nil
+	}
+	fmt.Fprintf(progress(ctx), "Kept %d backup(s), pruned %d backup(s).\n", len(plan.Keep), len(plan.Remove))
+	return nil
+}
+
+// dataProcessingAndCopy implements the approcachof uploading data/file in parts.
+// The data is read sequentially, and modified by the transforms the upload
+// writer applies, see Transform and UploadTransforms.
+// It returns the number of bytes written and the first read or write error;
+// reaching the end of the data is not an error.
+func dataProcessingAndCopy(upload io.Writer, dataReader io.Reader) (int64, error) {
+
+	var written int64
+	var buf = make([]byte, 32768)
+
+	// Loop to read the backup data in chunks and append the contents to the upload object.
+	for {
+		numOfBytesRead, readErr := dataReader.Read(buf)
+		if numOfBytesRead > 0 {
+			numOfBytesWritten, writeErr := upload.Write(buf[0:numOfBytesRead])
+			written += int64(numOfBytesWritten)
+			if writeErr == nil && numOfBytesWritten < numOfBytesRead {
+				writeErr = io.ErrShortWrite
+			}
+			if writeErr != nil {
+				return written, &CopyError{Op: "write", Written: written, Expected: -1, Err: writeErr}
+			}
+		}
+		if readErr == io.EOF {
+			return written, nil
+		}
+		if readErr != nil {
+			return written, &CopyError{Op: "read", Written: written, Expected: -1, Err: readErr}
+		}
+	}
+}
+
+// checkSize returns an error if the number of bytes written differs from
+// the expected size, unless the size is unknown.
+func checkSize(written, size int64) error {
+	if size < 0 || written == size {
+		return nil
+	}
+	return &CopyError{Op: "read", Written: written, Expected: size, Err: errSizeMismatch}
+}
+
+/*	Uncomment this Function if you are passing byte array(buffer) to the UploadData funtion.
+
+	func min(a, b int) int {
+
+	if a < b {
+		return a
+	}
+	return b
+}
+
+*/
@@ -0,0 +1,117 @@
+package connector
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"storj.io/uplink"
+)
+
+func TestSharePrefixes(t *testing.T) {
+	config := ConfigStorj{
+		Bucket:        "backups",
+		UploadPath:    "db01/",
+		SharePrefixes: []string{"logs", "sj://reports/2021/"},
+	}
+	extra := []uplink.SharePrefix{{Bucket: "logs"}, {Bucket: "reports", Prefix: "2021/"}}
+
+	tests := []struct {
+		keys []string
+		want uplink.SharePrefix
+	}{
+		{nil, uplink.SharePrefix{Bucket: "backups", Prefix: "db01/"}},
+		{[]string{"db01/dump.sql"}, uplink.SharePrefix{Bucket: "backups", Prefix: "db01/dump.sql"}},
+		{[]string{"db01/dump.sql", "db01/wal.log"}, uplink.SharePrefix{Bucket: "backups", Prefix: "db01/"}},
+	}
+	for _, test := range tests {
+		prefixes, err := SharePrefixes(config, test.keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := append([]uplink.SharePrefix{test.want}, extra...)
+		if !reflect.DeepEqual(prefixes, want) {
+			t.Errorf("keys %v: got %+v, want %+v", test.keys, prefixes, want)
+		}
+	}
+
+	config.SharePrefixes = []string{"sj:///prefix/"}
+	if _, err := SharePrefixes(config, nil); err == nil {
+		t.Error("expected an error for a share prefix without bucket")
+	}
+}
+
+// errReader fails every read with err.
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+// limitedWriter fails once more than limit bytes are written to it.
+type limitedWriter struct {
+	bytes.Buffer
+	limit int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.Len()+len(p) > w.limit {
+		n, _ := w.Buffer.Write(p[:w.limit-w.Len()])
+		return n, errors.New("connection reset")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestDataProcessingAndCopy(t *testing.T) {
+	data := strings.Repeat("0123456789", 10000)
+	readErr := errors.New("dump process exited with status 1")
+
+	tests := []struct {
+		name    string
+		reader  io.Reader
+		limit   int
+		written int64
+		op      string
+		err     error
+	}{
+		{name: "complete", reader: strings.NewReader(data), limit: len(data), written: int64(len(data))},
+		{name: "one byte reads", reader: iotest.OneByteReader(strings.NewReader(data[:100])), limit: len(data), written: 100},
+		{name: "data with error", reader: iotest.DataErrReader(strings.NewReader(data)), limit: len(data), written: int64(len(data))},
+		{name: "read error", reader: io.MultiReader(strings.NewReader(data[:50000]), errReader{readErr}), limit: len(data), written: 50000, op: "read", err: readErr},
+		{name: "write error", reader: strings.NewReader(data), limit: 40000, written: 40000, op: "write"},
+	}
+	for _, test := range tests {
+		writer := &limitedWriter{limit: test.limit}
+		written, err := dataProcessingAndCopy(writer, test.reader)
+		if written != test.written || int64(writer.Len()) != test.written {
+			t.Errorf("%s: wrote %d bytes, buffered %d, want %d", test.name, written, writer.Len(), test.written)
+		}
+
+		var copyErr *CopyError
+		if test.op == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", test.name, err)
+			}
+			continue
+		}
+		if !errors.As(err, &copyErr) || copyErr.Op != test.op || copyErr.Written != test.written {
+			t.Errorf("%s: got error %v", test.name, err)
+		}
+		if test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("%s: got error %v, want %v", test.name, err, test.err)
+		}
+	}
+
+	if err := checkSize(10, -1); err != nil {
+		t.Errorf("unknown size: %v", err)
+	}
+	if err := checkSize(10, 10); err != nil {
+		t.Errorf("expected size: %v", err)
+	}
+	err := checkSize(5, 10)
+	if !errors.Is(err, errSizeMismatch) || err.Error() != "read failed after 5 of 10 bytes: size differs from the expected size" {
+		t.Errorf("truncated: got %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package connector
+
+import "context"
+
+// Tracer is called when a traced function of this package starts.
+// The returned function is called when the traced function returns.
+// The CLI uses it to collect debug metrics.
+type Tracer func(function string) (end func())
+
+type tracerKey struct{}
+
+// WithTracer returns a copy of ctx that reports traced functions to tracer.
+func WithTracer(ctx context.Context, tracer Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, tracer)
+}
+
+// trace starts tracing function if ctx carries a tracer.
+func trace(ctx context.Context, function string) (end func()) {
+	if tracer, ok := ctx.Value(tracerKey{}).(Tracer); ok && tracer != nil {
+		return tracer(function)
+	}
+	return func() {}
+}
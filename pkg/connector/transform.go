@@ -0,0 +1,256 @@
+package connector
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"storj.io/uplink"
+)
+
+// MetadataTransforms is the custom metadata key listing the transforms
+// applied to the data of an object, comma separated in the order applied.
+const MetadataTransforms = "transforms"
+
+// Transform is a stage of the upload pipeline that rewrites the data of
+// an object, such as a compressor, and reverses it when restoring.
+type Transform interface {
+	// Name identifies the transform in the custom metadata of objects.
+	Name() string
+	// NewWriter returns a writer transforming the data written to it into w.
+	// Closing the writer flushes it without closing w.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	// NewReader returns a reader reversing the transform of the data read from r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// MetadataTransform is implemented by transforms recording additional custom
+// metadata on the objects they are applied to, e.g. the keys they depend on.
+type MetadataTransform interface {
+	Transform
+	Metadata() map[string]string
+}
+
+// TransformFactory creates a transform configured from the Storj configuration.
+type TransformFactory func(configStorj ConfigStorj) (Transform, error)
+
+var (
+	transformsMu sync.RWMutex
+	transforms   = make(map[string]TransformFactory)
+)
+
+// RegisterTransform makes a transform available under the given name, which
+// can then be selected in the configuration and reversed when restoring.
+// It panics if RegisterTransform is called twice with the same name or if factory is nil.
+func RegisterTransform(name string, factory TransformFactory) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+
+	if factory == nil {
+		panic("connector: RegisterTransform factory is nil")
+	}
+	if _, dup := transforms[name]; dup {
+		panic("connector: RegisterTransform called twice for transform " + name)
+	}
+	transforms[name] = factory
+}
+
+// NewTransform returns the transform registered under name.
+func NewTransform(name string, configStorj ConfigStorj) (Transform, error) {
+	transformsMu.RLock()
+	factory, ok := transforms[name]
+	transformsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown transform %q (registered: %s)", name, strings.Join(TransformNames(), ", "))
+	}
+	return factory(configStorj)
+}
+
+// TransformNames returns the sorted names of all registered transforms.
+func TransformNames() []string {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UploadTransforms returns the transforms applied to uploaded data,
+// in the order they are applied, as selected by the configuration.
+func UploadTransforms(configStorj ConfigStorj) ([]Transform, error) {
+	var chain []Transform
+	if configStorj.Compression != "" && configStorj.Compression != NoCompression {
+		transform, err := NewTransform(configStorj.Compression, configStorj)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, transform)
+	}
+	// Encrypt last, as encrypted data does not compress.
+	if len(configStorj.EncryptionRecipients) > 0 {
+		transform, err := NewTransform(AgeEncryption, configStorj)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, transform)
+	}
+	return chain, nil
+}
+
+// transformMetadata returns the custom metadata recording the transforms of chain,
+// including the metadata of every MetadataTransform.
+func transformMetadata(chain []Transform) uplink.CustomMetadata {
+	if len(chain) == 0 {
+		return nil
+	}
+	metadata := make(uplink.CustomMetadata)
+	names := make([]string, 0, len(chain))
+	for _, transform := range chain {
+		names = append(names, transform.Name())
+		if transform, ok := transform.(MetadataTransform); ok {
+			for key, value := range transform.Metadata() {
+				metadata[key] = value
+			}
+		}
+	}
+	metadata[MetadataTransforms] = strings.Join(names, ",")
+	return metadata
+}
+
+// transformWriter writes data through a chain of transforms.
+type transformWriter struct {
+	io.Writer
+	// closers flush the transforms, from the first applied to the last.
+	closers []io.Closer
+}
+
+// newTransformWriter returns a writer applying chain, in order, to the data written to w.
+// Closing it flushes every transform without closing w.
+func newTransformWriter(w io.Writer, chain []Transform) (*transformWriter, error) {
+	writer := &transformWriter{Writer: w}
+	closers := make([]io.Closer, len(chain))
+	for i := len(chain) - 1; i >= 0; i-- {
+		transformed, err := chain[i].NewWriter(writer.Writer)
+		if err != nil {
+			return nil, err
+		}
+		writer.Writer = transformed
+		closers[i] = transformed
+	}
+	writer.closers = closers
+	return writer, nil
+}
+
+// Close flushes the transforms.
+func (writer *transformWriter) Close() error {
+	for _, closer := range writer.closers {
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transformReader reads data through the reverse of a chain of transforms.
+type transformReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// newTransformReader returns a reader reversing the transforms recorded in
+// the custom metadata of an object, reading the stored data from r.
+func newTransformReader(r io.Reader, metadata uplink.CustomMetadata, configStorj ConfigStorj) (*transformReader, error) {
+	reader := &transformReader{Reader: r}
+	if metadata[MetadataTransforms] == "" {
+		return reader, nil
+	}
+
+	names := strings.Split(metadata[MetadataTransforms], ",")
+	for i := len(names) - 1; i >= 0; i-- {
+		transform, err := NewTransform(names[i], configStorj)
+		if err == nil {
+			var reversed io.ReadCloser
+			if reversed, err = transform.NewReader(reader.Reader); err == nil {
+				reader.Reader = reversed
+				reader.closers = append(reader.closers, reversed)
+			}
+		}
+		if err != nil {
+			_ = reader.Close()
+			return nil, err
+		}
+	}
+	return reader, nil
+}
+
+// Close releases the resources of the transforms.
+func (reader *transformReader) Close() error {
+	var err error
+	for i := len(reader.closers) - 1; i >= 0; i-- {
+		if closeErr := reader.closers[i].Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// Compression codecs selectable with the compression field of the configuration.
+const (
+	NoCompression   = "none"
+	GzipCompression = "gzip"
+	ZstdCompression = "zstd"
+)
+
+func init() {
+	RegisterTransform(GzipCompression, func(ConfigStorj) (Transform, error) { return gzipTransform{}, nil })
+	RegisterTransform(ZstdCompression, func(ConfigStorj) (Transform, error) { return zstdTransform{}, nil })
+}
+
+// gzipTransform compresses data with gzip.
+type gzipTransform struct{}
+
+func (gzipTransform) Name() string { return GzipCompression }
+
+func (gzipTransform) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func (gzipTransform) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// zstdTransform compresses data with Zstandard.
+type zstdTransform struct{}
+
+func (zstdTransform) Name() string { return ZstdCompression }
+
+func (zstdTransform) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w)
+}
+
+func (zstdTransform) NewReader(r io.Reader) (io.ReadCloser, error) {
+	decoder, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return zstdReader{decoder}, nil
+}
+
+// zstdReader adapts the Close method of a zstd.Decoder to io.Closer.
+type zstdReader struct {
+	*zstd.Decoder
+}
+
+func (reader zstdReader) Close() error {
+	reader.Decoder.Close()
+	return nil
+}
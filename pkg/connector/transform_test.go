@@ -0,0 +1,128 @@
+package connector
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestTransforms(t *testing.T) {
+	data := bytes.Repeat([]byte("INSERT INTO backups VALUES (1, 'connector');\n"), 1000)
+
+	for _, compression := range []string{"", NoCompression, GzipCompression, ZstdCompression} {
+		config := ConfigStorj{Compression: compression}
+		chain, err := UploadTransforms(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var stored bytes.Buffer
+		writer, err := newTransformWriter(&stored, chain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		metadata := transformMetadata(chain)
+		if compression != "" && compression != NoCompression {
+			if metadata[MetadataTransforms] != compression {
+				t.Errorf("%s: got metadata %v", compression, metadata)
+			}
+			if stored.Len() >= len(data)/10 {
+				t.Errorf("%s: compressed %d bytes to %d bytes", compression, len(data), stored.Len())
+			}
+		}
+
+		reader, err := newTransformReader(&stored, metadata, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		restored, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := reader.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(restored, data) {
+			t.Errorf("%q: restored %d bytes, want %d bytes", compression, len(restored), len(data))
+		}
+	}
+
+	if _, err := UploadTransforms(ConfigStorj{Compression: "lz4"}); err == nil {
+		t.Error("expected an error for an unknown compression")
+	}
+}
+
+func TestAgeTransform(t *testing.T) {
+	dir, err := ioutil.TempDir("", "age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	identityFile := filepath.Join(dir, "key.txt")
+	if err := ioutil.WriteFile(identityFile, []byte(identity.String()+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	recipient := identity.Recipient().String()
+	config := ConfigStorj{Compression: GzipCompression, EncryptionRecipients: []string{recipient}}
+	chain, err := UploadTransforms(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("pg_dump output")
+	var stored bytes.Buffer
+	writer, err := newTransformWriter(&stored, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(stored.Bytes(), data) {
+		t.Error("stored data is not encrypted")
+	}
+
+	metadata := transformMetadata(chain)
+	if got, want := metadata[MetadataTransforms], "gzip,age"; got != want {
+		t.Errorf("got transforms %q, want %q", got, want)
+	}
+	if got, want := metadata[MetadataAgeRecipients], AgeFingerprint(recipient); got != want {
+		t.Errorf("got recipients %q, want %q", got, want)
+	}
+
+	// Restoring needs the identity, not the recipients.
+	if _, err := newTransformReader(bytes.NewReader(stored.Bytes()), metadata, ConfigStorj{}); err == nil {
+		t.Error("expected an error without an identity file")
+	}
+	reader, err := newTransformReader(&stored, metadata, ConfigStorj{EncryptionIdentityFile: identityFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(restored, data) {
+		t.Errorf("restored %q, want %q", restored, data)
+	}
+}
@@ -0,0 +1,158 @@
+package connector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bool is a configuration flag. It is decoded from a JSON boolean
+// or from a string accepted by strconv.ParseBool, such as "true" or "0".
+type Bool bool
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var value bool
+	if err := json.Unmarshal(data, &value); err == nil {
+		*b = Bool(value)
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return b.UnmarshalText([]byte(text))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *Bool) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*b = false
+		return nil
+	}
+	value, err := strconv.ParseBool(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", text)
+	}
+	*b = Bool(value)
+	return nil
+}
+
+// Time is a point in time of the configuration. It is decoded from an RFC3339
+// timestamp, e.g. 2021-03-31T15:04:05Z, or from a duration relative to the
+// time the configuration is decoded, e.g. +720h. An empty string is the zero time.
+// A relative time is encoded as it was given, so that a configuration written
+// back is resolved again whenever it is loaded.
+type Time struct {
+	time.Time
+	// relative is the duration the time was decoded from, if any.
+	relative string
+}
+
+// legacyTimeFormat is the format accepted by earlier versions of the configuration.
+const legacyTimeFormat = "2006-01-02_15:04:05"
+
+// String returns the time in RFC3339 format, or "" if it is zero.
+func (t Time) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// text returns the relative duration the time was decoded from, or the time
+// as returned by String.
+func (t Time) text() string {
+	if t.relative != "" {
+		return t.relative
+	}
+	return t.String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t Time) MarshalText() ([]byte, error) {
+	return []byte(t.text()), nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t Time) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.text())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid time %s: want a string", data)
+	}
+	return t.UnmarshalText([]byte(text))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *Time) UnmarshalText(text []byte) error {
+	value := string(text)
+	t.relative = ""
+	switch {
+	case value == "":
+		t.Time = time.Time{}
+		return nil
+	case strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-"):
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid relative time %q: %w", value, err)
+		}
+		t.Time = time.Now().Add(d).UTC().Truncate(time.Second)
+		t.relative = value
+		return nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		parsed, err = time.Parse(legacyTimeFormat, value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid time %q: want an RFC3339 time like 2021-03-31T15:04:05Z or a relative duration like +720h", value)
+	}
+	t.Time = parsed
+	return nil
+}
+
+// Duration is a length of time of the configuration,
+// decoded from a string accepted by time.ParseDuration, e.g. 720h.
+type Duration time.Duration
+
+// String returns the duration formatted by time.Duration, or "" if it is zero.
+func (d Duration) String() string {
+	if d == 0 {
+		return ""
+	}
+	return time.Duration(d).String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*d = 0
+		return nil
+	}
+	value, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: want a duration like 720h", text)
+	}
+	*d = Duration(value)
+	return nil
+}
@@ -0,0 +1,153 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/source"
+)
+
+// DefaultWorkers is the number of parallel uploads used when none is configured.
+const DefaultWorkers = 4
+
+// Uploader uploads the items of a source concurrently over a shared session.
+type Uploader struct {
+	// Session is the open session the items are uploaded over.
+	Session *Session
+	// Config is the Storj configuration the items are uploaded with.
+	Config ConfigStorj
+	// SourceName is the name the source is registered under.
+	SourceName string
+	// Source opens the readers of the items.
+	Source source.Source
+	// KeyTemplate renders the object key of every item.
+	KeyTemplate *KeyTemplate
+	// Run identifies the run the items are uploaded in.
+	Run Run
+	// Workers is the number of parallel uploads, DefaultWorkers if not positive.
+	Workers int
+
+	// project, if set, is uploaded to instead of the project of Session.
+	project uploadProject
+}
+
+// UploadResult is the outcome of uploading a single item.
+type UploadResult struct {
+	Item     source.Item
+	Key      string
+	Checksum Checksum
+	Duration time.Duration
+	Err      error
+}
+
+// UploadReport aggregates the results of uploading a stream of items.
+type UploadReport struct {
+	Succeeded []UploadResult
+	Failed    []UploadResult
+}
+
+// Err returns the combined error of every failed upload, or nil.
+func (report *UploadReport) Err() error {
+	var group errs.Group
+	for _, result := range report.Failed {
+		group.Add(result.Err)
+	}
+	return group.Err()
+}
+
+// Print writes a summary of the report to w, listing every failed upload.
+func (report *UploadReport) Print(w io.Writer) {
+	fmt.Fprintf(w, "Uploaded %d item(s), %d failed.\n", len(report.Succeeded), len(report.Failed))
+	for _, result := range report.Failed {
+		fmt.Fprintf(w, "Failed %s: %v\n", result.Item.Key, result.Err)
+	}
+}
+
+// UploadAll uploads every item received on items until the channel is closed.
+// A failed upload does not stop the remaining ones; the failures are
+// collected in the returned report.
+func (uploader *Uploader) UploadAll(ctx context.Context, items <-chan source.Item) *UploadReport {
+
+	defer trace(ctx, "UploadAll")()
+
+	workers := uploader.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	results := make(chan UploadResult)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range items {
+				results <- uploader.upload(ctx, item)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	report := &UploadReport{}
+	for result := range results {
+		if result.Err != nil {
+			report.Failed = append(report.Failed, result)
+		} else {
+			report.Succeeded = append(report.Succeeded, result)
+		}
+	}
+	return report
+}
+
+// uploadSource streams the items of the source of the uploader to its
+// workers. It returns the report of the uploads and the error of the source.
+func uploadSource(ctx context.Context, uploader *Uploader) (*UploadReport, error) {
+	items := make(chan source.Item)
+	var sourceErr error
+	go func() {
+		defer close(items)
+		sourceErr = StreamSource(ctx, uploader.SourceName, uploader.Source, items)
+	}()
+
+	report := uploader.UploadAll(ctx, items)
+	return report, sourceErr
+}
+
+// upload uploads a single item.
+func (uploader *Uploader) upload(ctx context.Context, item source.Item) UploadResult {
+	start := time.Now()
+	result := UploadResult{Item: item}
+
+	result.Key, result.Err = uploader.KeyTemplate.Key(uploader.Run, item.Key)
+	if result.Err != nil {
+		result.Err = &ConfigError{Err: result.Err}
+		return result
+	}
+
+	if err := ctx.Err(); err != nil {
+		result.Err = &TransferError{Op: "upload", Key: result.Key, Err: err}
+		return result
+	}
+
+	reader, err := OpenSourceItem(ctx, uploader.SourceName, uploader.Source, item)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	project := uploader.project
+	if project == nil {
+		project = uplinkProject{uploader.Session.Project}
+	}
+	result.Checksum, result.Err = uploadData(ctx, project, uploader.Config, result.Key, reader, sourceInfo{Size: item.Size, ModTime: item.ModTime})
+	result.Duration = time.Since(start)
+	return result
+}
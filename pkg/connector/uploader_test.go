@@ -0,0 +1,190 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"storj.io/uplink"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/source"
+)
+
+// fakeSource streams its items and serves their key as their content.
+type fakeSource struct {
+	items []source.Item
+	// streamErr is returned after streaming the items.
+	streamErr error
+	// openErrs are returned when opening the items by key.
+	openErrs map[string]error
+
+	// workers is the number of items opened at the same time before any
+	// of them is read, ensuring that the uploads run in parallel.
+	workers int
+	ready   chan struct{}
+
+	mu      sync.Mutex
+	open    int
+	maxOpen int
+}
+
+func newFakeSource(workers int, keys ...string) *fakeSource {
+	src := &fakeSource{openErrs: make(map[string]error), workers: workers, ready: make(chan struct{})}
+	for _, key := range keys {
+		src.items = append(src.items, source.Item{Key: key, Path: key, Size: int64(len(key))})
+	}
+	return src
+}
+
+func (src *fakeSource) Configure(config json.RawMessage) error { return nil }
+
+func (src *fakeSource) Items(ctx context.Context) ([]source.Item, error) {
+	return src.items, src.streamErr
+}
+
+func (src *fakeSource) Stream(ctx context.Context, items chan<- source.Item) error {
+	for _, item := range src.items {
+		items <- item
+	}
+	return src.streamErr
+}
+
+func (src *fakeSource) Open(ctx context.Context, item source.Item) (io.ReadCloser, error) {
+	src.mu.Lock()
+	src.open++
+	if src.open > src.maxOpen {
+		src.maxOpen = src.open
+		if src.maxOpen == src.workers {
+			close(src.ready)
+		}
+	}
+	src.mu.Unlock()
+
+	select {
+	case <-src.ready:
+	case <-time.After(5 * time.Second):
+	}
+
+	if err := src.openErrs[item.Key]; err != nil {
+		src.closed()
+		return nil, err
+	}
+	return &fakeReader{Reader: strings.NewReader(item.Key), close: src.closed}, nil
+}
+
+func (src *fakeSource) closed() {
+	src.mu.Lock()
+	src.open--
+	src.mu.Unlock()
+}
+
+func (src *fakeSource) Close() error { return nil }
+
+type fakeReader struct {
+	io.Reader
+	close func()
+}
+
+func (reader *fakeReader) Close() error {
+	reader.close()
+	return nil
+}
+
+func newFakeUploader(t *testing.T, src *fakeSource, project *fakeProject) *Uploader {
+	keyTemplate, err := ParseKeyTemplate("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Uploader{
+		Config:      ConfigStorj{Bucket: "backups", UploadPath: "db01/"},
+		SourceName:  "fake",
+		Source:      src,
+		KeyTemplate: keyTemplate,
+		Run:         NewRun("fake"),
+		Workers:     src.workers,
+		project:     project,
+	}
+}
+
+func TestUploadAll(t *testing.T) {
+	src := newFakeSource(3, "a.sql", "b.sql", "c.sql", "d.sql", "unreadable.sql", "denied.sql", "e.sql")
+	src.openErrs["unreadable.sql"] = errors.New("permission denied")
+	project := newFakeProject()
+	project.objectErrs = map[string]error{"db01/denied.sql": uplink.ErrPermissionDenied}
+
+	ctx := WithProgress(context.Background(), ioutil.Discard)
+	report, err := uploadSource(ctx, newFakeUploader(t, src, project))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if src.maxOpen != src.workers {
+		t.Errorf("%d uploads in parallel, want %d", src.maxOpen, src.workers)
+	}
+
+	var succeeded, failed []string
+	for _, result := range report.Succeeded {
+		succeeded = append(succeeded, result.Key)
+		if result.Checksum.Size != int64(len(result.Item.Key)) {
+			t.Errorf("%s: got checksum %+v", result.Key, result.Checksum)
+		}
+		if got := project.committed["db01/"+result.Key]; got != result.Item.Key {
+			t.Errorf("%s: committed %q", result.Key, got)
+		}
+	}
+	for _, result := range report.Failed {
+		failed = append(failed, result.Key)
+	}
+	sort.Strings(succeeded)
+	sort.Strings(failed)
+	if fmt.Sprint(succeeded) != "[a.sql b.sql c.sql d.sql e.sql]" || fmt.Sprint(failed) != "[denied.sql unreadable.sql]" {
+		t.Errorf("succeeded %v, failed %v", succeeded, failed)
+	}
+
+	for _, result := range report.Failed {
+		var sourceErr *SourceError
+		var transferErr *TransferError
+		switch {
+		case result.Key == "unreadable.sql" && errors.As(result.Err, &sourceErr):
+		case result.Key == "denied.sql" && errors.As(result.Err, &transferErr) && errors.Is(result.Err, uplink.ErrPermissionDenied):
+		default:
+			t.Errorf("%s: got error %v", result.Key, result.Err)
+		}
+	}
+	if err := report.Err(); err == nil || !strings.Contains(err.Error(), "denied.sql") {
+		t.Errorf("got report error %v", err)
+	}
+
+	var out bytes.Buffer
+	report.Print(&out)
+	if !strings.HasPrefix(out.String(), "Uploaded 5 item(s), 2 failed.\n") {
+		t.Errorf("printed %q", out.String())
+	}
+}
+
+func TestUploadSourceError(t *testing.T) {
+	src := newFakeSource(1, "a.sql", "b.sql")
+	src.streamErr = errors.New("listing failed")
+	project := newFakeProject()
+
+	ctx := WithProgress(context.Background(), ioutil.Discard)
+	report, err := uploadSource(ctx, newFakeUploader(t, src, project))
+
+	// The items streamed before the failure are still uploaded.
+	var sourceErr *SourceError
+	if !errors.As(err, &sourceErr) || sourceErr.Source != "fake" || !errors.Is(err, src.streamErr) {
+		t.Errorf("got error %v", err)
+	}
+	if len(report.Succeeded) != 2 || len(report.Failed) != 0 || report.Err() != nil {
+		t.Errorf("got report %+v", report)
+	}
+}
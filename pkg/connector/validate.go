@@ -0,0 +1,246 @@
+package connector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// FieldError describes an invalid configuration field.
+type FieldError struct {
+	// Path is the JSON path of the field, e.g. retention.keepWithin.
+	Path string
+	Err  error
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+// ValidationError lists every invalid field of a configuration.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		lines = append(lines, field.Error())
+	}
+	return fmt.Sprintf("%d invalid field(s):\n\t%s", len(e.Fields), strings.Join(lines, "\n\t"))
+}
+
+// add records an invalid field.
+func (e *ValidationError) add(path string, err error) {
+	e.Fields = append(e.Fields, FieldError{Path: path, Err: err})
+}
+
+// has reports whether the field at path is already recorded.
+func (e *ValidationError) has(path string) bool {
+	for _, field := range e.Fields {
+		if field.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// err returns e if any field is invalid and nil otherwise.
+func (e *ValidationError) err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate checks the configuration and returns a *ValidationError
+// listing every invalid field.
+func (configStorj ConfigStorj) Validate() error {
+	var verr ValidationError
+
+	if configStorj.Bucket == "" {
+		verr.add("bucket", errors.New("is required"))
+	}
+	if configStorj.SerializedAccess == "" {
+		required := "is required unless serializedAccess is set"
+		if configStorj.APIKey == "" {
+			verr.add("apikey", errors.New(required))
+		}
+		if configStorj.Satellite == "" {
+			verr.add("satellite", errors.New(required))
+		}
+		if configStorj.EncryptionPassphrase == "" {
+			verr.add("encryptionpassphrase", errors.New(required))
+		}
+	}
+
+	if !configStorj.NotBefore.IsZero() && !configStorj.NotAfter.IsZero() &&
+		!configStorj.NotAfter.After(configStorj.NotBefore.Time) {
+		verr.add("notAfter", fmt.Errorf("%s is not after notBefore %s", configStorj.NotAfter, configStorj.NotBefore))
+	}
+	for i, sharePrefix := range configStorj.SharePrefixes {
+		if _, err := parseSharePrefix(sharePrefix); err != nil {
+			verr.add(fmt.Sprintf("sharePrefixes[%d]", i), err)
+		}
+	}
+	if configStorj.Compression == AgeEncryption {
+		verr.add("compression", errors.New("age is an encryption, configure encryptionRecipients instead"))
+	} else if configStorj.Compression != "" && configStorj.Compression != NoCompression {
+		if _, err := NewTransform(configStorj.Compression, configStorj); err != nil {
+			verr.add("compression", err)
+		}
+	}
+	for i, recipient := range configStorj.EncryptionRecipients {
+		if _, err := age.ParseX25519Recipient(recipient); err != nil {
+			verr.add(fmt.Sprintf("encryptionRecipients[%d]", i), err)
+		}
+	}
+	if _, err := ParseKeyTemplate(configStorj.KeyTemplate); err != nil {
+		verr.add("keyTemplate", err)
+	}
+	if configStorj.Workers < 0 {
+		verr.add("workers", fmt.Errorf("%d is negative", configStorj.Workers))
+	}
+
+	retention := configStorj.Retention
+	counts := []struct {
+		path  string
+		value int
+	}{
+		{"retention.keepLast", retention.KeepLast},
+		{"retention.keepDaily", retention.KeepDaily},
+		{"retention.keepWeekly", retention.KeepWeekly},
+		{"retention.keepMonthly", retention.KeepMonthly},
+	}
+	for _, count := range counts {
+		if count.value < 0 {
+			verr.add(count.path, fmt.Errorf("%d is negative", count.value))
+		}
+	}
+	if retention.KeepWithin < 0 {
+		verr.add("retention.keepWithin", fmt.Errorf("%s is negative", retention.KeepWithin))
+	}
+
+	multipart := configStorj.Multipart
+	sizes := []struct {
+		path  string
+		value int64
+	}{
+		{"multipart.threshold", multipart.Threshold},
+		{"multipart.partSize", multipart.PartSize},
+		{"multipart.retries", int64(multipart.Retries)},
+	}
+	for _, size := range sizes {
+		if size.value < 0 {
+			verr.add(size.path, fmt.Errorf("%d is negative", size.value))
+		}
+	}
+	if multipart.PartSize > 0 && multipart.PartSize < MinPartSize {
+		verr.add("multipart.partSize", fmt.Errorf("%d is less than the minimum part size %d (5 MiB)", multipart.PartSize, MinPartSize))
+	}
+	if multipart.Backoff < 0 {
+		verr.add("multipart.backoff", fmt.Errorf("%s is negative", multipart.Backoff))
+	}
+	if multipart.StateTTL < 0 {
+		verr.add("multipart.stateTTL", fmt.Errorf("%s is negative", multipart.StateTTL))
+	}
+
+	return verr.err()
+}
+
+// decodeFields decodes the JSON object data into config, a pointer to a struct,
+// and validates it if it implements Validate.
+// Unlike json.Unmarshal it does not stop at the first invalid value but
+// returns a *ValidationError listing every invalid field. Fields that cannot be
+// decoded are left unset, and only reported once.
+func decodeFields(data []byte, config interface{}) error {
+	var verr ValidationError
+	data = checkFields(data, reflect.TypeOf(config).Elem(), "", &verr)
+	if data == nil {
+		return verr.err()
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return err
+	}
+
+	validator, ok := config.(interface{ Validate() error })
+	if !ok {
+		return verr.err()
+	}
+	var invalid *ValidationError
+	if err := validator.Validate(); errors.As(err, &invalid) {
+		for _, field := range invalid.Fields {
+			if !verr.has(field.Path) {
+				verr.Fields = append(verr.Fields, field)
+			}
+		}
+	} else if err != nil {
+		return err
+	}
+	return verr.err()
+}
+
+// checkFields records every field of the struct type t in the JSON object data
+// that cannot be decoded, and returns data without them. It returns nil if
+// data is not an object.
+func checkFields(data []byte, t reflect.Type, path string, verr *ValidationError) []byte {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		if path == "" {
+			path = "$"
+		}
+		verr.add(path, errors.New("is not an object"))
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		// Object keys match field names case-insensitively, like json.Unmarshal.
+		for key, raw := range object {
+			if !strings.EqualFold(key, name) {
+				continue
+			}
+			if field.Type.Kind() == reflect.Struct && !isScalar(field.Type) {
+				if raw = checkFields(raw, field.Type, fieldPath, verr); raw == nil {
+					delete(object, key)
+				} else {
+					object[key] = raw
+				}
+				continue
+			}
+			if err := json.Unmarshal(raw, reflect.New(field.Type).Interface()); err != nil {
+				var typeErr *json.UnmarshalTypeError
+				if errors.As(err, &typeErr) {
+					err = fmt.Errorf("cannot use %s %s as %s", typeErr.Value, raw, typeErr.Type)
+				}
+				verr.add(fieldPath, err)
+				delete(object, key)
+			}
+		}
+	}
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil
+	}
+	return data
+}
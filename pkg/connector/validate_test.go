@@ -0,0 +1,78 @@
+package connector
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDecodeFields(t *testing.T) {
+	data := []byte(`{
+		"apikey": "key",
+		"satellite": "satellite",
+		"encryptionpassphrase": "passphrase",
+		"allowDownload": "yes",
+		"allowList": "true",
+		"allowDelete": false,
+		"notBefore": "0",
+		"notAfter": "2021-03-31T15:04:05Z",
+		"workers": "four",
+		"retention": {"keepLast": -1, "keepWithin": "3 days"},
+		"multipart": {"partSize": 1024}
+	}`)
+
+	var config ConfigStorj
+	err := decodeFields(data, &config)
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("got error %v, want a *ValidationError", err)
+	}
+	want := []string{"allowDownload", "notBefore", "workers", "retention.keepWithin", "bucket", "retention.keepLast", "multipart.partSize"}
+	if len(verr.Fields) != len(want) {
+		t.Fatalf("got %v, want invalid fields %v", verr, want)
+	}
+	for i, field := range verr.Fields {
+		if field.Path != want[i] {
+			t.Errorf("invalid field %d: got %s, want %s", i, field.Path, want[i])
+		}
+	}
+
+	if !config.AllowList || config.AllowDelete {
+		t.Errorf("got allowList %v and allowDelete %v", config.AllowList, config.AllowDelete)
+	}
+	if !config.NotAfter.Equal(time.Date(2021, 3, 31, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("got notAfter %v", config.NotAfter)
+	}
+}
+
+func TestTime(t *testing.T) {
+	var relative Time
+	if err := relative.UnmarshalText([]byte("+720h")); err != nil {
+		t.Fatal(err)
+	}
+	if until := time.Until(relative.Time); until < 719*time.Hour || until > 720*time.Hour {
+		t.Errorf("+720h is %v from now", until)
+	}
+	// The relative time is written back as given, to be resolved on every load.
+	if text, err := relative.MarshalText(); err != nil || string(text) != "+720h" {
+		t.Errorf("+720h marshaled as %q, %v", text, err)
+	}
+	if data, err := json.Marshal(relative); err != nil || string(data) != `"+720h"` {
+		t.Errorf("+720h marshaled as %s, %v", data, err)
+	}
+
+	var absolute Time
+	if err := absolute.UnmarshalText([]byte("2021-03-31T15:04:05Z")); err != nil {
+		t.Fatal(err)
+	}
+	if text, err := absolute.MarshalText(); err != nil || string(text) != "2021-03-31T15:04:05Z" {
+		t.Errorf("absolute time marshaled as %q, %v", text, err)
+	}
+
+	var empty Time
+	if err := empty.UnmarshalText(nil); err != nil || !empty.IsZero() {
+		t.Errorf("got %v, %v for an empty time", empty, err)
+	}
+}
@@ -0,0 +1,194 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/zeebo/errs"
+	"storj.io/uplink"
+)
+
+// VerifyResult is the outcome of verifying a single object.
+type VerifyResult struct {
+	Key string
+	// Recorded is the checksum recorded when uploading, zero if none was.
+	Recorded Checksum
+	// Actual is the checksum of the downloaded data.
+	Actual Checksum
+	// Overwritten is set if the object was not verified against the
+	// checksum of a run as a later run stored the same key.
+	Overwritten bool
+	Err         error
+}
+
+// Skipped reports whether the object was not verified as no checksum is
+// recorded or as it was overwritten by a later run.
+func (result *VerifyResult) Skipped() bool {
+	return result.Err == nil && (result.Recorded.IsZero() || result.Overwritten)
+}
+
+// VerifyReport aggregates the results of verifying backups.
+type VerifyReport struct {
+	Results []VerifyResult
+}
+
+// Err returns an error if any object failed verification, or nil.
+func (report *VerifyReport) Err() error {
+	failed := 0
+	for _, result := range report.Results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return &TransferError{Op: "verify", Err: fmt.Errorf("%d of %d backup(s) failed verification", failed, len(report.Results))}
+}
+
+// Print writes the result of every verified object and a summary to w.
+func (report *VerifyReport) Print(w io.Writer) {
+	var verified, skipped, failed int
+	for _, result := range report.Results {
+		switch {
+		case result.Err != nil:
+			failed++
+			fmt.Fprintf(w, "FAILED  %s: %v\n", result.Key, result.Err)
+		case result.Overwritten:
+			skipped++
+			fmt.Fprintf(w, "SKIPPED %s: overwritten by a later run\n", result.Key)
+		case result.Skipped():
+			skipped++
+			fmt.Fprintf(w, "SKIPPED %s: no checksum recorded\n", result.Key)
+		default:
+			verified++
+			fmt.Fprintf(w, "OK      %s\n", result.Key)
+		}
+	}
+	fmt.Fprintf(w, "Verified %d backup(s), %d failed, %d skipped.\n", verified, failed, skipped)
+}
+
+// VerifyBackups downloads the objects below the upload path whose keys start
+// with prefix and compares the checksum of their data with the recorded one.
+// A failed object does not stop the remaining ones.
+func VerifyBackups(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, prefix string) (*VerifyReport, error) {
+
+	defer trace(ctx, "VerifyBackups")()
+
+	objects, err := ListBackups(ctx, project, configStorj, prefix, true)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VerifyReport{}
+	for _, object := range objects {
+		if object.IsPrefix {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return report, &TransferError{Op: "verify", Key: object.Key, Err: err}
+		}
+		report.Results = append(report.Results, VerifyObject(ctx, project, configStorj, object.Key))
+	}
+	if len(report.Results) == 0 {
+		return nil, &TransferError{Op: "verify", Key: configStorj.UploadPath + prefix, Err: uplink.ErrObjectNotFound}
+	}
+	return report, nil
+}
+
+// VerifyRun verifies the objects in the manifest of the run with the given ID
+// against the checksums recorded in the manifest. Objects overwritten by a
+// later run are skipped, see ManifestObject.Overwritten.
+func VerifyRun(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, runID string) (*VerifyReport, error) {
+
+	defer trace(ctx, "VerifyRun")()
+
+	manifest, err := LoadManifest(ctx, project, configStorj, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VerifyReport{}
+	for _, entry := range manifest.Objects {
+		if err := ctx.Err(); err != nil {
+			return report, &TransferError{Op: "verify", Key: entry.Key, Err: err}
+		}
+		report.Results = append(report.Results, verifyObject(ctx, project, configStorj, entry))
+	}
+	return report, nil
+}
+
+// VerifyObject downloads the object stored under key, reverses its transforms
+// and compares the checksum of the data with the one recorded in its metadata.
+func VerifyObject(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, key string) VerifyResult {
+	return verifyObject(ctx, project, configStorj, ManifestObject{Key: key})
+}
+
+// verifyObject implements VerifyObject, comparing with the checksum of entry
+// instead of the one in the metadata of the object if it is not zero.
+func verifyObject(ctx context.Context, project *uplink.Project, configStorj ConfigStorj, entry ManifestObject) VerifyResult {
+
+	defer trace(ctx, "VerifyObject")()
+
+	key := entry.Key
+	result := VerifyResult{Key: key, Recorded: entry.Checksum}
+
+	download, err := project.DownloadObject(ctx, configStorj.Bucket, key, nil)
+	if err != nil {
+		result.Err = &TransferError{Op: "verify", Key: key, Err: err}
+		return result
+	}
+	if entry.Overwritten(download.Info().Custom) {
+		result.Overwritten = true
+		_ = download.Close()
+		return result
+	}
+	fmt.Fprintf(progress(ctx), "Verifying %s...\n", key)
+
+	if result.Recorded.IsZero() {
+		result.Recorded, err = ChecksumFromMetadata(download.Info().Custom)
+	}
+	if err != nil || result.Recorded.IsZero() {
+		if err != nil {
+			result.Err = &TransferError{Op: "verify", Key: key, Err: err}
+		}
+		_ = download.Close()
+		return result
+	}
+
+	reader, err := newTransformReader(download, download.Info().Custom, configStorj)
+	if err != nil {
+		_ = download.Close()
+		result.Err = &TransferError{Op: "verify", Key: key, Err: err}
+		return result
+	}
+
+	writer := newChecksumWriter(ioutil.Discard)
+	_, err = io.Copy(writer, reader)
+	err = errs.Combine(err, reader.Close(), download.Close())
+	if err == nil {
+		result.Actual = writer.Checksum()
+		err = compareChecksums(result.Recorded, result.Actual)
+	}
+	if err != nil {
+		result.Err = &TransferError{Op: "verify", Key: key, Err: err}
+	}
+	return result
+}
+
+// errChecksumMismatch is returned when the data of an object does not match its checksum.
+var errChecksumMismatch = errors.New("checksum mismatch")
+
+// compareChecksums returns an error describing the difference of actual from recorded.
+func compareChecksums(recorded, actual Checksum) error {
+	if actual.Size != recorded.Size {
+		return fmt.Errorf("%w: got %d bytes, recorded %d bytes", errChecksumMismatch, actual.Size, recorded.Size)
+	}
+	if actual.SHA256 != recorded.SHA256 {
+		return fmt.Errorf("%w: got sha256 %s, recorded %s", errChecksumMismatch, actual.SHA256, recorded.SHA256)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package connector
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestVerifyReport(t *testing.T) {
+	recorded := Checksum{SHA256: "aa", Size: 1}
+	report := &VerifyReport{Results: []VerifyResult{
+		{Key: "a.sql", Recorded: recorded, Actual: recorded},
+		{Key: "b.sql", Recorded: recorded, Err: errChecksumMismatch},
+		{Key: "c.sql"},
+		{Key: "d.sql", Recorded: recorded, Overwritten: true},
+	}}
+
+	var out bytes.Buffer
+	report.Print(&out)
+	want := `OK      a.sql
+FAILED  b.sql: checksum mismatch
+SKIPPED c.sql: no checksum recorded
+SKIPPED d.sql: overwritten by a later run
+Verified 1 backup(s), 1 failed, 2 skipped.
+`
+	if out.String() != want {
+		t.Errorf("printed\n%s\nwant\n%s", out.String(), want)
+	}
+
+	var transferErr *TransferError
+	if err := report.Err(); !errors.As(err, &transferErr) || transferErr.Op != "verify" {
+		t.Errorf("got error %v", err)
+	}
+	report.Results = report.Results[2:]
+	if err := report.Err(); err != nil {
+		t.Errorf("skipped objects failed: %v", err)
+	}
+}
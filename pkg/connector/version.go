@@ -0,0 +1,4 @@
+package connector
+
+// Version is the version of the connector framework, recorded in the manifest of every run.
+const Version = "1.0.7"
@@ -0,0 +1,212 @@
+// Package local implements a source that reads files from the local disk.
+// It is registered under the name "local".
+//
+// The configured path may point to a single file, a directory that is
+// walked recursively, or a glob pattern. Include and exclude patterns are
+// matched against both the slash separated path relative to the source
+// root and the base name of every file.
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/source"
+)
+
+// Name is the name under which the local source is registered.
+const Name = "local"
+
+func init() {
+	source.Register(Name, func() source.Source { return &Source{} })
+}
+
+// Config stores the local path and the filters applied to it.
+type Config struct {
+	Path    string   `json:"path"`
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+}
+
+// Source backs up files from the local disk.
+type Source struct {
+	Config Config
+}
+
+// Configure parses the local source configuration.
+func (s *Source) Configure(config json.RawMessage) error {
+	if err := json.Unmarshal(config, &s.Config); err != nil {
+		return fmt.Errorf("local: could not parse configuration: %w", err)
+	}
+	if s.Config.Path == "" {
+		return errors.New("local: path is required")
+	}
+	for _, pattern := range append(append([]string{}, s.Config.Include...), s.Config.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("local: invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Describe returns the parsed configuration for display.
+func (s *Source) Describe() interface{} {
+	return s.Config
+}
+
+// Items returns every file below the configured path that passes the
+// include and exclude filters. A single file is returned under its base name,
+// files found in directories and glob matches keep their relative path.
+func (s *Source) Items(ctx context.Context) ([]source.Item, error) {
+	var items []source.Item
+	err := s.walk(ctx, func(item source.Item) error {
+		items = append(items, item)
+		return nil
+	})
+	return items, err
+}
+
+// Stream sends the items returned by Items while the files are being walked.
+func (s *Source) Stream(ctx context.Context, items chan<- source.Item) error {
+	return s.walk(ctx, func(item source.Item) error {
+		select {
+		case items <- item:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// walk calls emit for every file below the configured path
+// that passes the include and exclude filters.
+func (s *Source) walk(ctx context.Context, emit func(source.Item) error) error {
+	target := filepath.Clean(s.Config.Path)
+
+	// A path is only a glob pattern if no file has that literal name,
+	// such as report[1].txt.
+	var roots []string
+	base := target
+	info, statErr := os.Stat(target)
+	if os.IsNotExist(statErr) && hasMeta(target) {
+		matches, err := filepath.Glob(target)
+		if err != nil {
+			return fmt.Errorf("local: %w", err)
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("local: no files match %q", target)
+		}
+		roots = matches
+		base = globBase(target)
+	} else {
+		if statErr != nil {
+			return fmt.Errorf("local: %w", statErr)
+		}
+		if !info.IsDir() {
+			base = filepath.Dir(target)
+		}
+		roots = []string{target}
+	}
+
+	for _, root := range roots {
+		err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			rel, err := filepath.Rel(base, file)
+			if err != nil {
+				return err
+			}
+			key := filepath.ToSlash(rel)
+
+			if info.IsDir() {
+				if file != root && s.excluded(key) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !info.Mode().IsRegular() || s.excluded(key) || !s.included(key) {
+				return nil
+			}
+
+			return emit(source.Item{
+				Key:     key,
+				Path:    file,
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("local: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Open opens the file backing the item.
+func (s *Source) Open(ctx context.Context, item source.Item) (io.ReadCloser, error) {
+	file, err := os.Open(filepath.Clean(item.Path))
+	if err != nil {
+		return nil, fmt.Errorf("local: %w", err)
+	}
+	return file, nil
+}
+
+// Close is a no-op for the local source.
+func (s *Source) Close() error {
+	return nil
+}
+
+// included reports whether key passes the include filter.
+// An empty include list includes every file.
+func (s *Source) included(key string) bool {
+	return len(s.Config.Include) == 0 || matchAny(s.Config.Include, key)
+}
+
+// excluded reports whether key matches any of the exclude patterns.
+func (s *Source) excluded(key string) bool {
+	return matchAny(s.Config.Exclude, key)
+}
+
+// matchAny reports whether the slash separated key or its base name
+// matches any of the patterns.
+func matchAny(patterns []string, key string) bool {
+	name := path.Base(key)
+	for _, pattern := range patterns {
+		pattern = filepath.ToSlash(pattern)
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMeta reports whether p contains any of the glob meta characters.
+func hasMeta(p string) bool {
+	return strings.ContainsAny(p, `*?[`)
+}
+
+// globBase returns the longest leading directory of pattern
+// that does not contain glob meta characters.
+func globBase(pattern string) string {
+	dir := filepath.Dir(pattern)
+	for hasMeta(dir) {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
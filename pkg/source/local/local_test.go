@@ -0,0 +1,101 @@
+package local_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/storj-thirdparty/connector-framework/pkg/source/local"
+)
+
+func TestItems(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	for _, name := range []string{
+		"a.txt",
+		"b.log",
+		"sub/c.txt",
+		"sub/deep/d.txt",
+		"cache/e.txt",
+		"report[1].txt",
+	} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config local.Config
+		keys   []string
+	}{
+		{
+			name:   "single file",
+			config: local.Config{Path: filepath.Join(dir, "sub", "c.txt")},
+			keys:   []string{"c.txt"},
+		},
+		{
+			name:   "directory",
+			config: local.Config{Path: dir},
+			keys:   []string{"a.txt", "b.log", "cache/e.txt", "report[1].txt", "sub/c.txt", "sub/deep/d.txt"},
+		},
+		{
+			name:   "include and exclude",
+			config: local.Config{Path: dir, Include: []string{"*.txt"}, Exclude: []string{"cache", "sub/deep/*"}},
+			keys:   []string{"a.txt", "report[1].txt", "sub/c.txt"},
+		},
+		{
+			name:   "glob",
+			config: local.Config{Path: filepath.Join(dir, "s*", "*.txt")},
+			keys:   []string{"sub/c.txt"},
+		},
+		{
+			name:   "literal file with glob characters",
+			config: local.Config{Path: filepath.Join(dir, "report[1].txt")},
+			keys:   []string{"report[1].txt"},
+		},
+		{
+			name:   "glob matching no literal file",
+			config: local.Config{Path: filepath.Join(dir, "report*.txt")},
+			keys:   []string{"report[1].txt"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, err := json.Marshal(test.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var src local.Source
+			if err := src.Configure(config); err != nil {
+				t.Fatal(err)
+			}
+			items, err := src.Items(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var keys []string
+			for _, item := range items {
+				keys = append(keys, item.Key)
+			}
+			if !reflect.DeepEqual(keys, test.keys) {
+				t.Errorf("got keys %v, want %v", keys, test.keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// Package source defines the interface implemented by connector sources
+// and a registry that the CLI uses to look them up by name.
+//
+// A new connector is added by implementing Source in its own package and
+// calling Register from that package's init function:
+//
+//	func init() {
+//		source.Register("mysource", func() source.Source { return &MySource{} })
+//	}
+//
+// The package then only needs to be imported (usually with a blank import)
+// by the binary for the source to become available to the store command.
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Item describes a single piece of data produced by a source.
+type Item struct {
+	// Key is the name of the item relative to the upload path.
+	Key string
+	// Path identifies the item within the source, e.g. a local file path.
+	Path string
+	// Size is the size of the item in bytes, or -1 if unknown.
+	Size int64
+	// ModTime is the last modification time of the item, if known.
+	ModTime time.Time
+}
+
+// Source is the interface implemented by every connector source.
+type Source interface {
+	// Configure parses the source specific JSON configuration.
+	Configure(config json.RawMessage) error
+	// Items enumerates the items that should be backed up.
+	Items(ctx context.Context) ([]Item, error)
+	// Open returns a reader for the contents of the given item.
+	// The reader is read sequentially, so it may be any stream, such as
+	// the output of a dump process or an HTTP response body.
+	// The caller is responsible for closing the reader.
+	Open(ctx context.Context, item Item) (io.ReadCloser, error)
+	// Close releases any resources held by the source.
+	Close() error
+}
+
+// Describer is implemented by sources that expose their parsed configuration
+// so it can be printed. Fields tagged `sensitive:"true"`, such as passwords
+// and tokens, are redacted when printed.
+//
+// Describe is also called before Configure; the type of the returned value
+// determines the environment variables that override the configuration.
+type Describer interface {
+	Describe() interface{}
+}
+
+// Streamer is implemented by sources that can send their items while they
+// are still being enumerated, so that uploads start before the listing ends.
+type Streamer interface {
+	// Stream sends every item to be backed up on items.
+	// It must not close the channel.
+	Stream(ctx context.Context, items chan<- Item) error
+}
+
+// Stream sends the items of src on items, using Streamer if the source
+// implements it and Items otherwise. It does not close the channel.
+func Stream(ctx context.Context, src Source, items chan<- Item) error {
+	if streamer, ok := src.(Streamer); ok {
+		return streamer.Stream(ctx, items)
+	}
+
+	all, err := src.Items(ctx)
+	if err != nil {
+		return err
+	}
+	for _, item := range all {
+		select {
+		case items <- item:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Factory creates a new, unconfigured source.
+type Factory func() Source
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a source available under the given name.
+// It panics if Register is called twice with the same name or if factory is nil.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("source: Register factory is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("source: Register called twice for source " + name)
+	}
+	registry[name] = factory
+}
+
+// New returns a new, unconfigured source registered under name.
+func New(name string) (Source, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("source: unknown source %q (registered: %v)", name, Names())
+	}
+	return factory(), nil
+}
+
+// Names returns the sorted names of all registered sources.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,122 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeSource is a source with a fixed list of items that does not stream.
+type fakeSource struct {
+	items []Item
+	err   error
+}
+
+func (src *fakeSource) Configure(config json.RawMessage) error { return nil }
+
+func (src *fakeSource) Items(ctx context.Context) ([]Item, error) { return src.items, src.err }
+
+func (src *fakeSource) Open(ctx context.Context, item Item) (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader(item.Key)), nil
+}
+
+func (src *fakeSource) Close() error { return nil }
+
+// register registers a fakeSource under each name and returns a function
+// that removes them from the registry again.
+func register(t *testing.T, names ...string) func() {
+	t.Helper()
+	for _, name := range names {
+		Register(name, func() Source { return &fakeSource{} })
+	}
+	return func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		for _, name := range names {
+			delete(registry, name)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	defer register(t, "zeta", "alpha", "mid")()
+
+	if got, want := Names(), []string{"alpha", "mid", "zeta"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got names %v, want %v", got, want)
+	}
+
+	src, err := New("mid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := src.(*fakeSource); !ok {
+		t.Errorf("got source %T", src)
+	}
+	if other, _ := New("mid"); other == src {
+		t.Errorf("New returned the same source twice")
+	}
+
+	if _, err := New("missing"); err == nil || !strings.Contains(err.Error(), `"missing"`) {
+		t.Errorf("unknown source: got error %v", err)
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	defer register(t, "dup")()
+
+	tests := []struct {
+		name    string
+		factory Factory
+		panic   string
+	}{
+		{name: "dup", factory: func() Source { return &fakeSource{} }, panic: "source: Register called twice for source dup"},
+		{name: "nil", factory: nil, panic: "source: Register factory is nil"},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if got := recover(); got != test.panic {
+					t.Errorf("%s: got panic %v, want %q", test.name, got, test.panic)
+				}
+			}()
+			Register(test.name, test.factory)
+		}()
+	}
+	if _, err := New("nil"); err == nil {
+		t.Errorf("nil factory was registered")
+	}
+}
+
+func TestStream(t *testing.T) {
+	items := []Item{{Key: "a", Size: 1}, {Key: "b", Size: -1}}
+
+	ch := make(chan Item, len(items))
+	if err := Stream(context.Background(), &fakeSource{items: items}, ch); err != nil {
+		t.Fatal(err)
+	}
+	close(ch)
+	var got []Item
+	for item := range ch {
+		got = append(got, item)
+	}
+	if !reflect.DeepEqual(got, items) {
+		t.Errorf("got items %v, want %v", got, items)
+	}
+
+	listErr := errors.New("list failed")
+	if err := Stream(context.Background(), &fakeSource{err: listErr}, make(chan Item)); !errors.Is(err, listErr) {
+		t.Errorf("got error %v, want %v", err, listErr)
+	}
+
+	// A canceled stream stops instead of blocking on the unread channel.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Stream(ctx, &fakeSource{items: items}, make(chan Item)); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled: got error %v", err)
+	}
+}